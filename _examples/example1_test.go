@@ -6,10 +6,9 @@ import (
 )
 
 func TestExample1(t *testing.T) {
-	is := testy.New(t)
-	defer func() { t.Logf(is.Done()) }() // Line 10
-	is.Error("First failure")            // Line 11
-	checkTrue(is, 1+1 == 3)              // Line 12
+	is := testy.New(t)        // Line 9
+	is.Error("First failure") // Line 10
+	checkTrue(is, 1+1 == 3)   // Line 11
 }
 
 func checkTrue(is *testy.T, cond bool) {
@@ -7,9 +7,8 @@ import (
 
 func TestExample3(t *testing.T) {
 	is := testy.New(t)
-	defer func() { t.Logf(is.Done()) }()
 
 	for i := 1; i <= 5; i++ {
-		is.Label("Checking", i).True(i == 3) // Line 13
+		is.Label("Checking", i).True(i == 3) // Line 12
 	}
 }
@@ -7,10 +7,9 @@ import (
 
 func TestExample4(t *testing.T) {
 	is := testy.New(t)
-	defer func() { t.Logf(is.Done()) }()
 
 	for i := -1; i <= 2; i++ {
-		checkEvenPositive(is, i) // Line 13
+		checkEvenPositive(is, i) // Line 12
 	}
 }
 
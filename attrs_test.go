@@ -0,0 +1,56 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+func TestWith(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.NewCase(mock, "Attrs")
+
+	user := test.Label("Lookup").With("id", 7).With("name", "Bob Smith")
+	user.Equal(1, 2)
+	user.With("id", 8).With("empty", "").Error("replaced")
+	user.Fail()
+	test.Error("plain")
+
+	output := test.Output()
+	expect := []string{
+		`attrs_test.go:22: Lookup: id=7 name="Bob Smith": Values were not equal:`,
+		`attrs_test.go:23: Lookup: name="Bob Smith" id=8 empty="": replaced$`,
+		`attrs_test.go:25: plain$`,
+	}
+	for i, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[i]); !ok {
+			t.Errorf("Output %d didn't match '%s': '%s'", i, e, output[i])
+		}
+	}
+
+	events := test.Events()
+	if len(events) != 5 {
+		t.Fatalf("Expected 5 events, got %d: %v", len(events), events)
+	}
+	if events[0].Kind != testy.EventCaseStart || events[0].Case != "Attrs" {
+		t.Errorf("First event was not the case start: %v", events[0])
+	}
+	wantAttrs := []testy.Attr{{Key: "id", Value: 7}, {Key: "name", Value: "Bob Smith"}}
+	if !reflect.DeepEqual(events[1].Attrs, wantAttrs) {
+		t.Errorf("Event attributes were wrong: %v", events[1].Attrs)
+	}
+	if events[3].Kind != testy.EventFailure || events[3].Message != "" || !reflect.DeepEqual(events[3].Attrs, wantAttrs) {
+		t.Errorf("Fail event was wrong: %v", events[3])
+	}
+	if events[4].Attrs != nil {
+		t.Errorf("Unlabeled event had attributes: %v", events[4].Attrs)
+	}
+}
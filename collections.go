@@ -0,0 +1,231 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Len checks if its argument has length 'want'; if not, it logs an error
+// with the lengths and the value on subsequent lines.  It works on strings,
+// slices, arrays, maps and channels.
+func (t *T) Len(got interface{}, want int) {
+	n, ok := lenOf(got)
+	switch {
+	case !ok:
+		t.report(Event{Kind: EventFailure, Message: "Value has no length:\n" + diag("   Got", got), Got: got, Want: want})
+		t.fail()
+	case n != want:
+		t.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Lengths were not equal:\n%s%s%s", diag("   Got", n), diag("Wanted", want), diag(" Value", got)),
+			Got:     got,
+			Want:    want,
+		})
+		t.fail()
+	}
+}
+
+// Empty checks if its argument is nil or has length zero; if not, it logs
+// an error showing the value.
+func (t *T) Empty(got interface{}) {
+	if got == nil {
+		return
+	}
+	n, ok := lenOf(got)
+	switch {
+	case !ok:
+		t.report(Event{Kind: EventFailure, Message: "Value has no length:\n" + diag("   Got", got), Got: got})
+		t.fail()
+	case n != 0:
+		t.report(Event{Kind: EventFailure, Message: "Value was not empty:\n" + diag("   Got", got), Got: got})
+		t.fail()
+	}
+}
+
+// NotEmpty inverts the logic of Empty but is otherwise similar.
+func (t *T) NotEmpty(got interface{}) {
+	n, ok := lenOf(got)
+	switch {
+	case got != nil && !ok:
+		t.report(Event{Kind: EventFailure, Message: "Value has no length:\n" + diag("   Got", got), Got: got})
+		t.fail()
+	case n == 0:
+		t.report(Event{Kind: EventFailure, Message: "Value was empty:\n" + diag("   Got", got), Got: got})
+		t.fail()
+	}
+}
+
+// Contains checks if 'container' holds 'item'; if not, it logs an error
+// with both on subsequent lines.  A string contains a substring, a slice
+// or array contains an element equal to item according to
+// reflect.DeepEqual, and a map contains a key.
+func (t *T) Contains(container, item interface{}) {
+	found, ok := containsItem(container, item)
+	switch {
+	case !ok:
+		t.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Can't look for an item in this value:\n%s%s", diag("   Got", container), diag("  Item", item)),
+			Got:     container,
+			Want:    item,
+		})
+		t.fail()
+	case !found:
+		t.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Value did not contain item:\n%s%s", diag("   Got", container), diag("  Item", item)),
+			Got:     container,
+			Want:    item,
+		})
+		t.fail()
+	}
+}
+
+// ElementsMatch checks if two slices or arrays have the same elements,
+// ignoring order; if not, it logs an error with both values, the elements
+// of 'got' that weren't wanted and the elements of 'want' that were
+// missing.  Elements are compared with reflect.DeepEqual and each one must
+// appear the same number of times in both.
+func (t *T) ElementsMatch(got, want interface{}) {
+	g, w := reflect.ValueOf(got), reflect.ValueOf(want)
+	if !isList(g) || !isList(w) {
+		t.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Can't compare elements unless both values are slices or arrays:\n%s%s", diag("   Got", got), diag("Wanted", want)),
+			Got:     got,
+			Want:    want,
+		})
+		t.fail()
+		return
+	}
+	if extra, missing := unmatchedElements(g, w); len(extra) > 0 || len(missing) > 0 {
+		t.report(Event{
+			Kind: EventFailure,
+			Message: fmt.Sprintf("Elements did not match:\n%s%s  Extra: %s\nMissing: %s",
+				diag("    Got", got), diag(" Wanted", want), formatList(extra), formatList(missing)),
+			Got:  got,
+			Want: want,
+		})
+		t.fail()
+	}
+}
+
+// Subset checks if every element of the slice or array 'got' is also an
+// element of the slice or array 'want'; if not, it logs an error with both
+// values and the elements of 'got' that weren't found.  Elements are
+// compared with reflect.DeepEqual, and how many times they appear doesn't
+// matter.
+func (t *T) Subset(got, want interface{}) {
+	g, w := reflect.ValueOf(got), reflect.ValueOf(want)
+	if !isList(g) || !isList(w) {
+		t.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Can't compare elements unless both values are slices or arrays:\n%s%s", diag("   Got", got), diag("Wanted", want)),
+			Got:     got,
+			Want:    want,
+		})
+		t.fail()
+		return
+	}
+	var extra []reflect.Value
+	for i := 0; i < g.Len(); i++ {
+		if !listContains(w, g.Index(i).Interface()) {
+			extra = append(extra, g.Index(i))
+		}
+	}
+	if len(extra) > 0 {
+		t.report(Event{
+			Kind: EventFailure,
+			Message: fmt.Sprintf("Value was not a subset:\n%s%s Extra: %s",
+				diag("   Got", got), diag("Wanted", want), formatList(extra)),
+			Got:  got,
+			Want: want,
+		})
+		t.fail()
+	}
+}
+
+// lenOf returns the length of a string, slice, array, map or channel, and
+// false for anything else.
+func lenOf(x interface{}) (int, bool) {
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		return v.Len(), true
+	}
+	return 0, false
+}
+
+// containsItem reports whether container holds item, and false for ok if
+// container can't hold items.
+func containsItem(container, item interface{}) (found, ok bool) {
+	v := reflect.ValueOf(container)
+	switch v.Kind() {
+	case reflect.String:
+		s, isString := item.(string)
+		return isString && strings.Contains(v.String(), s), true
+	case reflect.Slice, reflect.Array:
+		return listContains(v, item), true
+	case reflect.Map:
+		// An uncomparable item, such as a slice, can't be a key, and
+		// looking it up in a map with interface keys would panic.
+		k := reflect.ValueOf(item)
+		if !k.IsValid() || !k.Type().AssignableTo(v.Type().Key()) || !k.Comparable() {
+			return false, true
+		}
+		return v.MapIndex(k).IsValid(), true
+	}
+	return false, false
+}
+
+func listContains(list reflect.Value, item interface{}) bool {
+	for i := 0; i < list.Len(); i++ {
+		if reflect.DeepEqual(list.Index(i).Interface(), item) {
+			return true
+		}
+	}
+	return false
+}
+
+// unmatchedElements pairs up equal elements of two lists and returns those
+// left over from each.
+func unmatchedElements(got, want reflect.Value) (extra, missing []reflect.Value) {
+	matched := make([]bool, want.Len())
+	for i := 0; i < got.Len(); i++ {
+		g := got.Index(i).Interface()
+		found := false
+		for j := 0; j < want.Len(); j++ {
+			if !matched[j] && reflect.DeepEqual(g, want.Index(j).Interface()) {
+				matched[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			extra = append(extra, got.Index(i))
+		}
+	}
+	for j, m := range matched {
+		if !m {
+			missing = append(missing, want.Index(j))
+		}
+	}
+	return extra, missing
+}
+
+// formatList formats elements like a slice, using formatValue so strings
+// are quoted.
+func formatList(vs []reflect.Value) string {
+	parts := make([]string, len(vs))
+	for i, v := range vs {
+		parts[i] = formatValue(v)
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
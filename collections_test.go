@@ -0,0 +1,95 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+func TestCollectionHelpers(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	var nilSlice []int
+	m := map[string]int{"a": 1, "b": 2}
+
+	// not failures
+	test.Len([]int{1, 2, 3}, 3)
+	test.Len("foo", 3)
+	test.Len(m, 2)
+	test.Len([2]string{}, 2)
+	test.Empty(nil)
+	test.Empty(nilSlice)
+	test.Empty("")
+	test.NotEmpty(m)
+	test.Contains("foobar", "oba")
+	test.Contains([]string{"a", "b"}, "b")
+	test.Contains(m, "a")
+	test.Contains([]interface{}{1, []int{2}}, []int{2})
+	test.ElementsMatch([]int{1, 2, 2, 3}, []int{2, 3, 1, 2})
+	test.ElementsMatch([3]int{1, 2, 3}, []int{3, 2, 1})
+	test.Subset([]string{"b", "a", "b"}, []string{"a", "b", "c"})
+	test.Subset(nilSlice, []int{1})
+
+	// failures
+	test.Len([]int{1, 2}, 3)
+	test.Len(42, 1)
+	test.Empty(m)
+	test.NotEmpty(nil)
+	test.Contains("foobar", "baz")
+	test.Contains([]int{1, 2}, int64(1))
+	test.Contains(m, 1)
+	test.Contains(42, 4)
+	test.ElementsMatch([]int{1, 2, 2, 4}, []int{1, 2, 3, 3})
+	test.ElementsMatch(m, []int{1})
+	test.Label("Roles").Subset([]string{"admin", "user", "root"}, []string{"user"})
+
+	if fc := test.FailCount(); fc != 11 {
+		t.Fatalf("Incorrect FailCount. Got %d, but expected 11", fc)
+	}
+
+	output := test.Output()
+	expect := []string{
+		`(?s)collections_test.go:\d+: Lengths were not equal:\n\s+Got: 2 \(int\)\n\s+Wanted: 3 \(int\)\n\s+Value: \[1 2\] \(\[\]int\)$`,
+		`(?s)Value has no length:\n\s+Got: 42 \(int\)$`,
+		`(?s)Value was not empty:\n\s+Got: map\[a:1 b:2\] \(map\[string\]int\)$`,
+		`(?s)Value was empty:\n\s+Got: nil$`,
+		`(?s)Value did not contain item:\n\s+Got: "foobar"\n\s+Item: "baz"$`,
+		`(?s)Value did not contain item:\n\s+Got: \[1 2\] \(\[\]int\)\n\s+Item: 1 \(int64\)$`,
+		`(?s)Value did not contain item:\n\s+Got: map\[a:1 b:2\] \(map\[string\]int\)\n\s+Item: 1 \(int\)$`,
+		`(?s)Can't look for an item in this value:\n\s+Got: 42 \(int\)\n\s+Item: 4 \(int\)$`,
+		`(?s)Elements did not match:\n\s+Got: \[1 2 2 4\] \(\[\]int\)\n\s+Wanted: \[1 2 3 3\] \(\[\]int\)\n\s+Extra: \[2 4\]\n\s*Missing: \[3 3\]$`,
+		`(?s)Can't compare elements unless both values are slices or arrays:`,
+		`(?s)Roles: Value was not a subset:\n\s+Got: \[admin user root\] \(\[\]string\)\n\s+Wanted: \[user\] \(\[\]string\)\n\s+Extra: \["admin" "root"\]$`,
+	}
+	for i, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[i]); !ok {
+			t.Errorf("Output %d didn't match '%s': '%s'", i, e, output[i])
+		}
+	}
+}
+
+func TestContainsUncomparableItem(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	m := map[interface{}]int{"a": 1, 2: 2}
+	test.Contains(m, 2)
+	test.Contains(m, []int{1})
+
+	output := test.Output()
+	if len(output) != 1 {
+		t.Fatalf("Expected 1 failure, got %d: %v", len(output), output)
+	}
+	e := `(?s)Value did not contain item:\n.*\n\s+Item: \[1\] \(\[\]int\)$`
+	if ok, _ := regexp.MatchString(e, output[0]); !ok {
+		t.Errorf("Output didn't match '%s': '%s'", e, output[0])
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// maxDiffs limits how many differences are reported for one comparison so
+// that a badly mismatched collection doesn't flood the log.
+const maxDiffs = 20
+
+// isComposite reports whether a value is a struct, map, slice or array, or
+// a non-nil pointer to one.  These are the values that get a structural
+// diff instead of whole-value diagnostics.
+func isComposite(x interface{}) bool {
+	v := reflect.ValueOf(x)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
+
+// diffValues walks two values in parallel and returns a description of
+// each path at which they differ, following the rules of reflect.DeepEqual.
+func diffValues(got, want interface{}) []string {
+	return diffValuesWithin(got, want, nil)
+}
+
+// diffValuesWithin is like diffValues, but if tol is not nil, floating
+// point numbers only differ if they are not within that tolerance.
+func diffValuesWithin(got, want interface{}, tol *tolerance) []string {
+	d := &differ{visited: make(map[visit]bool), tol: tol}
+	d.walk("", reflect.ValueOf(got), reflect.ValueOf(want))
+	if d.omitted > 0 {
+		d.diffs = append(d.diffs, fmt.Sprintf("... and %d more differences", d.omitted))
+	}
+	return d.diffs
+}
+
+// visit records a pair of references already being compared, to stop
+// cyclic data structures from recursing forever.
+type visit struct {
+	got  uintptr
+	want uintptr
+	typ  reflect.Type
+}
+
+type differ struct {
+	diffs   []string
+	omitted int
+	visited map[visit]bool
+	tol     *tolerance
+}
+
+func (d *differ) report(path string, format string, args ...interface{}) {
+	if len(d.diffs) >= maxDiffs {
+		d.omitted++
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if path != "" {
+		msg = path + ": " + msg
+	}
+	d.diffs = append(d.diffs, msg)
+}
+
+func (d *differ) mismatch(path string, got, want reflect.Value) {
+	d.report(path, "got %s, want %s", formatValue(got), formatValue(want))
+}
+
+// seen marks a pair of references as visited and reports whether it had
+// been visited before.
+func (d *differ) seen(got, want reflect.Value) bool {
+	v := visit{got.Pointer(), want.Pointer(), got.Type()}
+	if d.visited[v] {
+		return true
+	}
+	d.visited[v] = true
+	return false
+}
+
+func (d *differ) walk(path string, got, want reflect.Value) {
+	if !got.IsValid() || !want.IsValid() {
+		if got.IsValid() != want.IsValid() {
+			d.mismatch(path, got, want)
+		}
+		return
+	}
+
+	if got.Type() != want.Type() {
+		d.report(path, "got %s (%v), want %s (%v)",
+			formatValue(got), got.Type(), formatValue(want), want.Type())
+		return
+	}
+
+	switch got.Kind() {
+	case reflect.Ptr:
+		if got.IsNil() || want.IsNil() {
+			if got.IsNil() != want.IsNil() {
+				d.mismatch(path, got, want)
+			}
+			return
+		}
+		if got.Pointer() == want.Pointer() || d.seen(got, want) {
+			return
+		}
+		d.walk(path, got.Elem(), want.Elem())
+	case reflect.Interface:
+		if got.IsNil() || want.IsNil() {
+			if got.IsNil() != want.IsNil() {
+				d.mismatch(path, got, want)
+			}
+			return
+		}
+		d.walk(path, got.Elem(), want.Elem())
+	case reflect.Struct:
+		for i := 0; i < got.NumField(); i++ {
+			d.walk(path+"."+got.Type().Field(i).Name, got.Field(i), want.Field(i))
+		}
+	case reflect.Slice:
+		if got.IsNil() || want.IsNil() {
+			if got.IsNil() != want.IsNil() {
+				d.mismatch(path, got, want)
+			}
+			return
+		}
+		if got.Len() == want.Len() && got.Pointer() == want.Pointer() {
+			return
+		}
+		if d.seen(got, want) {
+			return
+		}
+		d.walkList(path, got, want)
+	case reflect.Array:
+		d.walkList(path, got, want)
+	case reflect.Map:
+		if got.IsNil() || want.IsNil() {
+			if got.IsNil() != want.IsNil() {
+				d.mismatch(path, got, want)
+			}
+			return
+		}
+		if got.Pointer() == want.Pointer() || d.seen(got, want) {
+			return
+		}
+		d.walkMap(path, got, want)
+	case reflect.Func:
+		// Like reflect.DeepEqual, functions are only equal if both are nil.
+		if !got.IsNil() || !want.IsNil() {
+			d.mismatch(path, got, want)
+		}
+	case reflect.Float32, reflect.Float64:
+		if d.tol == nil {
+			if !scalarEqual(got, want) {
+				d.mismatch(path, got, want)
+			}
+			return
+		}
+		if diff, ok := d.tol.within(got.Float(), want.Float(), got.Type().Bits()); !ok {
+			d.report(path, "got %s, want %s, difference %s", formatValue(got), formatValue(want), diff)
+		}
+	default:
+		if !scalarEqual(got, want) {
+			d.mismatch(path, got, want)
+		}
+	}
+}
+
+func (d *differ) walkList(path string, got, want reflect.Value) {
+	if got.Len() != want.Len() {
+		d.report(path, "got length %d, want length %d", got.Len(), want.Len())
+	}
+	n := got.Len()
+	if want.Len() > n {
+		n = want.Len()
+	}
+	for i := 0; i < n; i++ {
+		elemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= got.Len():
+			d.report(elemPath, "got <none>, want %s", formatValue(want.Index(i)))
+		case i >= want.Len():
+			d.report(elemPath, "got %s, want <none>", formatValue(got.Index(i)))
+		default:
+			d.walk(elemPath, got.Index(i), want.Index(i))
+		}
+	}
+}
+
+func (d *differ) walkMap(path string, got, want reflect.Value) {
+	// Keys are visited in order of their formatted value so that output is
+	// stable from run to run.
+	keys := got.MapKeys()
+	for _, k := range want.MapKeys() {
+		if !got.MapIndex(k).IsValid() {
+			keys = append(keys, k)
+		}
+	}
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = formatValue(k)
+	}
+	sort.Sort(keySorter{keys, names})
+
+	for i, k := range keys {
+		keyPath := fmt.Sprintf("%s[%s]", path, names[i])
+		g, w := got.MapIndex(k), want.MapIndex(k)
+		switch {
+		case !g.IsValid():
+			d.report(keyPath, "got <none>, want %s", formatValue(w))
+		case !w.IsValid():
+			d.report(keyPath, "got %s, want <none>", formatValue(g))
+		default:
+			d.walk(keyPath, g, w)
+		}
+	}
+}
+
+type keySorter struct {
+	keys  []reflect.Value
+	names []string
+}
+
+func (s keySorter) Len() int           { return len(s.keys) }
+func (s keySorter) Less(i, j int) bool { return s.names[i] < s.names[j] }
+func (s keySorter) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.names[i], s.names[j] = s.names[j], s.names[i]
+}
+
+// scalarEqual compares values of the same non-composite type.  It works on
+// unexported struct fields, where Interface() is not allowed.
+func scalarEqual(got, want reflect.Value) bool {
+	switch got.Kind() {
+	case reflect.Bool:
+		return got.Bool() == want.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return got.Int() == want.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return got.Uint() == want.Uint()
+	case reflect.Float32, reflect.Float64:
+		return got.Float() == want.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return got.Complex() == want.Complex()
+	case reflect.String:
+		return got.String() == want.String()
+	case reflect.Chan, reflect.UnsafePointer:
+		return got.Pointer() == want.Pointer()
+	}
+	return false
+}
+
+// formatValue renders a value for a diff line.  Strings are quoted and nil
+// references are shown as "nil" so they can be told apart from empty ones.
+func formatValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		if v.IsNil() {
+			return "nil"
+		}
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Interface:
+		return formatValue(v.Elem())
+	}
+	return fmt.Sprintf("%v", v)
+}
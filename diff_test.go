@@ -0,0 +1,122 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+type address struct {
+	Street string
+	Zip    string
+}
+
+type user struct {
+	Name    string
+	Address *address
+	Tags    map[string]int
+	age     int
+}
+
+type directory struct {
+	Users []user
+}
+
+type node struct {
+	Value int
+	Next  *node
+}
+
+func TestStructuralDiff(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	users := func() []user {
+		return []user{
+			{Name: "Alice", Address: &address{"1 Main St", "02138"}, age: 30},
+			{Name: "Bob", Address: &address{"2 Main St", "02138"}, Tags: map[string]int{"a": 1, "b": 2}},
+		}
+	}
+	got, want := directory{users()}, directory{users()}
+	got.Users[1].Address.Zip = "02139"
+	got.Users[1].Tags["b"] = 3
+	got.Users[1].Tags["c"] = 4
+	delete(got.Users[1].Tags, "a")
+	got.Users[0].age = 31
+	test.Equal(got, want)
+
+	test.Equal([]int{1, 2, 3}, []int{1, 5})
+	test.Equal([]int(nil), []int{})
+	test.Equal(map[int]string{1: "one"}, map[int]string{1: "uno"})
+
+	loop1 := &node{Value: 1}
+	loop1.Next = loop1
+	loop2 := &node{Value: 1}
+	loop2.Next = &node{Value: 2, Next: loop2}
+	test.Equal(loop1, loop2)
+
+	// different types fall back to whole values
+	test.Equal([]int{1}, []int64{1})
+
+	many1, many2 := make([]int, 50), make([]int, 50)
+	for i := range many2 {
+		many2[i] = i + 1
+	}
+	test.Equal(many1, many2)
+
+	output := test.Output()
+	if len(output) != 7 {
+		t.Fatalf("Expected 7 failures, got %d: %v", len(output), output)
+	}
+
+	expect := []string{
+		`(?m)^\s+\.Users\[0\]\.age: got 31, want 30$`,
+		`(?m)^\s+\.Users\[1\]\.Address\.Zip: got "02139", want "02138"$`,
+		`(?m)^\s+\.Users\[1\]\.Tags\["a"\]: got <none>, want 1$`,
+		`(?m)^\s+\.Users\[1\]\.Tags\["b"\]: got 3, want 2$`,
+		`(?m)^\s+\.Users\[1\]\.Tags\["c"\]: got 4, want <none>$`,
+	}
+	for _, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[0]); !ok {
+			t.Errorf("Struct diff didn't match '%s': '%s'", e, output[0])
+		}
+	}
+	if strings.Contains(output[0], "Name") || strings.Contains(output[0], "Street") {
+		t.Errorf("Struct diff reported equal fields: '%s'", output[0])
+	}
+
+	expect = []string{
+		`(?m)^\s+got length 3, want length 2$`,
+		`(?m)^\s+\[1\]: got 2, want 5$`,
+		`(?m)^\s+\[2\]: got 3, want <none>$`,
+	}
+	for _, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[1]); !ok {
+			t.Errorf("Slice diff didn't match '%s': '%s'", e, output[1])
+		}
+	}
+
+	if ok, _ := regexp.MatchString(`(?m)^\s+got nil, want \[\]$`, output[2]); !ok {
+		t.Errorf("Nil slice diff was wrong: '%s'", output[2])
+	}
+	if ok, _ := regexp.MatchString(`(?m)^\s+\[1\]: got "one", want "uno"$`, output[3]); !ok {
+		t.Errorf("Map diff was wrong: '%s'", output[3])
+	}
+	if ok, _ := regexp.MatchString(`(?m)^\s+\.Next\.Value: got 1, want 2$`, output[4]); !ok {
+		t.Errorf("Cyclic diff was wrong: '%s'", output[4])
+	}
+	if ok, _ := regexp.MatchString(`(?m)^\s+Got: \[1\] \(\[\]int\)`, output[5]); !ok {
+		t.Errorf("Mismatched types didn't fall back to 'got': '%s'", output[5])
+	}
+	if ok, _ := regexp.MatchString(`(?m)^\s+\.\.\. and 30 more differences$`, output[6]); !ok {
+		t.Errorf("Long diff wasn't truncated: '%s'", output[6])
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+// TestAutoDoneChild is run in a child process by TestAutoDone so that the
+// output delivered by the cleanup function can be inspected.
+func TestAutoDoneChild(t *testing.T) {
+	if os.Getenv("TESTY_TEST_CHILD") != "TestAutoDoneChild" {
+		t.Skip("only runs as a child of TestAutoDone")
+	}
+	is := testy.New(t)
+	is.Error("delivered automatically")
+
+	manual := testy.NewCase(t, "Manual case").ManualDone()
+	manual.Log("never delivered")
+
+	done := testy.NewCase(t, "Done case")
+	done.Log("delivered by hand")
+	t.Log(done.Done())
+}
+
+func TestAutoDone(t *testing.T) {
+	log := childOutput("TestAutoDoneChild")
+
+	if ok, _ := regexp.MatchString(`done_test.go:\d+: TestAutoDoneChild: 1 test failed`, log); !ok {
+		t.Errorf("Summary wasn't delivered automatically: '%s'", log)
+	}
+	if ok, _ := regexp.MatchString(`done_test.go:\d+: delivered automatically`, log); !ok {
+		t.Errorf("Error wasn't delivered automatically: '%s'", log)
+	}
+	if strings.Contains(log, "never delivered") {
+		t.Errorf("ManualDone didn't stop delivery: '%s'", log)
+	}
+	if n := strings.Count(log, "delivered by hand"); n != 1 {
+		t.Errorf("Done output was delivered %d times: '%s'", n, log)
+	}
+}
+
+// childOutput runs a single test in a child process and returns its
+// verbose output.  The test should skip itself unless the TESTY_TEST_CHILD
+// environment variable is set to its name.
+func childOutput(name string) string {
+	cmd := exec.Command(os.Args[0], "-test.run=^"+name+"$", "-test.v")
+	cmd.Env = append(os.Environ(), "TESTY_TEST_CHILD="+name)
+	out, _ := cmd.CombinedOutput()
+	return string(out)
+}
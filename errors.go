@@ -0,0 +1,156 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// maxChainDepth limits how deep errorChain follows wrapped errors, in case
+// an error wraps itself.
+const maxChainDepth = 32
+
+// NoError checks if its argument is nil; if not, it logs an error showing
+// every layer of the error chain.
+func (t *T) NoError(err error) {
+	if err != nil {
+		t.report(Event{Kind: EventFailure, Message: "Unexpected error:\n" + errorChain(err), Got: err})
+		t.fail()
+	}
+}
+
+// ErrorIs checks if any error in err's chain matches target according to
+// errors.Is; if not, it logs an error showing the target and the chain.
+func (t *T) ErrorIs(err, target error) {
+	if !errors.Is(err, target) {
+		t.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Error chain did not include target %s:\n%s", describeError(target), errorChain(err)),
+			Got:     err,
+			Want:    target,
+		})
+		t.fail()
+	}
+}
+
+// ErrorAs checks if any error in err's chain can be assigned to the value
+// target points to, according to errors.As.  If so, target is set to that
+// error; if not, it logs an error showing the chain.  Unlike errors.As, it
+// logs an error instead of panicking if target is not a non-nil pointer to
+// a type that implements error or to an interface type.
+func (t *T) ErrorAs(err error, target interface{}) {
+	if msg := checkAsTarget(target); msg != "" {
+		t.report(Event{Kind: EventFailure, Message: msg, Got: err, Want: target})
+		t.fail()
+		return
+	}
+	if !errors.As(err, target) {
+		t.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Error chain did not include a %v:\n%s", reflect.TypeOf(target).Elem(), errorChain(err)),
+			Got:     err,
+			Want:    target,
+		})
+		t.fail()
+	}
+}
+
+// ErrorContains checks if err is not nil and its message contains substr;
+// if not, it logs an error showing the chain.
+func (t *T) ErrorContains(err error, substr string) {
+	if err == nil || !strings.Contains(err.Error(), substr) {
+		t.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Error message did not contain %q:\n%s", substr, errorChain(err)),
+			Got:     err,
+			Want:    substr,
+		})
+		t.fail()
+	}
+}
+
+// ErrorMatches checks if err is not nil and its message matches the
+// regular expression pattern; if not, it logs an error showing the chain.
+func (t *T) ErrorMatches(err error, pattern string) {
+	re, reErr := regexp.Compile(pattern)
+	if reErr != nil {
+		t.report(Event{Kind: EventFailure, Message: fmt.Sprintf("Invalid pattern: %v", reErr), Got: err, Want: pattern})
+		t.fail()
+		return
+	}
+	if err == nil || !re.MatchString(err.Error()) {
+		t.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Error message did not match /%s/:\n%s", pattern, errorChain(err)),
+			Got:     err,
+			Want:    pattern,
+		})
+		t.fail()
+	}
+}
+
+// errorChain describes an error and every error it wraps, one per line,
+// with its concrete type.  Errors that wrap several errors, like those
+// from errors.Join, have each wrapped error indented beneath them:
+//
+// 	*fmt.wrapError: "load config: open app.conf: no such file or directory"
+// 	  *fs.PathError: "open app.conf: no such file or directory"
+// 	    syscall.Errno: "no such file or directory"
+func errorChain(err error) string {
+	if err == nil {
+		return "nil"
+	}
+	buf := new(bytes.Buffer)
+	writeChain(buf, err, 0)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func writeChain(buf *bytes.Buffer, err error, depth int) {
+	fmt.Fprintf(buf, "%s%s\n", strings.Repeat("  ", depth), describeError(err))
+	if depth >= maxChainDepth {
+		return
+	}
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			if inner != nil {
+				writeChain(buf, inner, depth+1)
+			}
+		}
+	case interface{ Unwrap() error }:
+		if inner := e.Unwrap(); inner != nil {
+			writeChain(buf, inner, depth+1)
+		}
+	}
+}
+
+func describeError(err error) string {
+	if err == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%T: %q", err, err.Error())
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// checkAsTarget returns a message describing why target can't be used with
+// errors.As, or an empty string if it can.
+func checkAsTarget(target interface{}) string {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Sprintf("ErrorAs target must be a non-nil pointer, not %s", describeValue(target))
+	}
+	if elem := v.Type().Elem(); elem.Kind() != reflect.Interface && !elem.Implements(errorType) {
+		return fmt.Sprintf("ErrorAs target must point to an interface or an error type, not %v", elem)
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+var errNotFound = errors.New("not found")
+
+type codeError struct {
+	Code int
+}
+
+func (e *codeError) Error() string {
+	return fmt.Sprintf("code %d", e.Code)
+}
+
+func TestErrorHelpers(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	_, openErr := os.Open("/no/such/file")
+	wrapped := fmt.Errorf("load config: %w", openErr)
+	joined := errors.Join(&codeError{404}, fmt.Errorf("lookup: %w", errNotFound))
+
+	// not failures
+	var pathErr *fs.PathError
+	var codeErr *codeError
+	test.NoError(nil)
+	test.ErrorIs(wrapped, fs.ErrNotExist)
+	test.ErrorIs(joined, errNotFound)
+	test.ErrorAs(wrapped, &pathErr)
+	test.ErrorAs(joined, &codeErr)
+	test.ErrorContains(wrapped, "load config")
+	test.ErrorMatches(joined, `^code \d+\n`)
+
+	if pathErr == nil || pathErr.Path != "/no/such/file" {
+		t.Errorf("ErrorAs() didn't set target: %v", pathErr)
+	}
+	if codeErr == nil || codeErr.Code != 404 {
+		t.Errorf("ErrorAs() didn't set target: %v", codeErr)
+	}
+
+	// failures
+	test.NoError(wrapped)
+	test.ErrorIs(joined, fs.ErrNotExist)
+	test.ErrorIs(nil, errNotFound)
+	test.ErrorAs(wrapped, &codeErr)
+	test.ErrorAs(wrapped, codeErr)
+	test.ErrorContains(nil, "anything")
+	test.ErrorMatches(wrapped, `^open`)
+	test.ErrorMatches(wrapped, `(`)
+
+	output := test.Output()
+	if len(output) != 8 {
+		t.Fatalf("Expected 8 failures, got %d: %v", len(output), output)
+	}
+
+	expect := []string{
+		`(?s)errors_test.go:\d+: Unexpected error:\n\s+\*fmt.wrapError: "load config: open /no/such/file: no such file or directory"\n\s+  \*fs.PathError: "open /no/such/file: no such file or directory"\n\s+    syscall.Errno: "no such file or directory"$`,
+		`(?s)Error chain did not include target \*errors.errorString: "file does not exist":\n\s+\*errors.joinError: "code 404\\nlookup: not found"\n\s+  \*testy_test.codeError: "code 404"\n\s+  \*fmt.wrapError: "lookup: not found"\n\s+    \*errors.errorString: "not found"$`,
+		`(?s)Error chain did not include target \*errors.errorString: "not found":\n\s+nil$`,
+		`(?s)Error chain did not include a \*testy_test.codeError:\n\s+\*fmt.wrapError`,
+		`ErrorAs target must point to an interface or an error type, not testy_test.codeError`,
+		`(?s)Error message did not contain "anything":\n\s+nil$`,
+		`(?s)Error message did not match /\^open/:\n\s+\*fmt.wrapError`,
+		`Invalid pattern: error parsing regexp`,
+	}
+	for i, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[i]); !ok {
+			t.Errorf("Output %d didn't match '%s': '%s'", i, e, output[i])
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xdg/testy/internal/recording"
+)
+
+// Eventually calls cond repeatedly, waiting 'interval' between calls, until
+// a call records no failures or 'timeout' has passed.  Each call gets a
+// scratch facade whose failures and logs are kept apart from the test;
+// use it for checks inside cond.  The scratch facade's FailNow, Fatal and
+// Require helpers end only that call.  If the condition is never met, an
+// error is logged with the messages of the last call on subsequent lines.
+//
+// Each call runs in its own goroutine.  A call still running when the
+// timeout passes is abandoned, so cond should not block indefinitely.
+func (t *T) Eventually(cond func(is *T), timeout, interval time.Duration) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	var last attempt
+	for n := 1; ; n++ {
+		pending := t.attempt(cond)
+		select {
+		case last = <-pending:
+		case <-deadline.C:
+			// Give a call that started before the deadline one more
+			// interval to finish, so a quick condition isn't mistaken for
+			// a stuck one.
+			select {
+			case last = <-pending:
+				if !last.passed {
+					t.report(Event{Kind: EventFailure, Message: notMetMessage(timeout, n, last)})
+					t.fail()
+				}
+			case <-time.After(interval):
+				t.report(Event{
+					Kind: EventFailure,
+					Message: fmt.Sprintf("Condition was not met within %v; attempt %d was still running. Previous attempt:\n%s",
+						timeout, n, last.describe()),
+				})
+				t.fail()
+			}
+			return
+		}
+		if last.passed {
+			return
+		}
+
+		select {
+		case <-deadline.C:
+			t.report(Event{Kind: EventFailure, Message: notMetMessage(timeout, n, last)})
+			t.fail()
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func notMetMessage(timeout time.Duration, attempts int, last attempt) string {
+	return fmt.Sprintf("Condition was not met within %v after %d attempts; last attempt:\n%s",
+		timeout, attempts, last.describe())
+}
+
+// Consistently calls cond repeatedly, waiting 'interval' between calls,
+// until 'duration' has passed, and checks that every call records no
+// failures.  Like Eventually, each call gets a scratch facade.  At the
+// first call that fails, an error is logged with that call's messages on
+// subsequent lines.
+//
+// As with Eventually, a call still running when the duration passes gets
+// one more interval to finish.  If it is still running after that, it is
+// abandoned and an error is logged, so a blocked condition fails the check
+// instead of hanging the test.
+func (t *T) Consistently(cond func(is *T), duration, interval time.Duration) {
+	deadline := time.NewTimer(duration)
+	defer deadline.Stop()
+
+	for n := 1; ; n++ {
+		pending := t.attempt(cond)
+		var a attempt
+		select {
+		case a = <-pending:
+		case <-deadline.C:
+			select {
+			case a = <-pending:
+				if !a.passed {
+					t.report(Event{Kind: EventFailure, Message: notHeldMessage(duration, n, a)})
+					t.fail()
+				}
+			case <-time.After(interval):
+				t.report(Event{
+					Kind:    EventFailure,
+					Message: fmt.Sprintf("Condition did not hold for %v; attempt %d was still running", duration, n),
+				})
+				t.fail()
+			}
+			return
+		}
+		if !a.passed {
+			t.report(Event{Kind: EventFailure, Message: notHeldMessage(duration, n, a)})
+			t.fail()
+			return
+		}
+
+		select {
+		case <-deadline.C:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func notHeldMessage(duration time.Duration, attempt int, a attempt) string {
+	return fmt.Sprintf("Condition did not hold for %v; attempt %d failed:\n%s",
+		duration, attempt, a.describe())
+}
+
+// attempt is the outcome of one call of a polled condition.
+type attempt struct {
+	passed   bool
+	messages []string
+}
+
+// describe formats the messages of an attempt, indented for inclusion in
+// an error message.
+func (a attempt) describe() string {
+	if a.messages == nil {
+		return "  (no attempt finished)"
+	}
+	if len(a.messages) == 0 {
+		return "  (failed without a message)"
+	}
+	lines := make([]string, len(a.messages))
+	for i, m := range a.messages {
+		lines[i] = "  " + strings.Replace(m, "\n\t", "\n    ", -1)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// attempt calls cond with a scratch facade in a new goroutine and returns
+// a channel that receives the outcome when the call ends.
+func (t *T) attempt(cond func(is *T)) <-chan attempt {
+	tb := recording.NewScratch(t.test, "")
+	is := &T{
+		test:      tb,
+		context:   &accumulator{manual: true, scratch: true},
+		caseName:  t.caseName,
+		callDepth: 1,
+		nanEqual:  t.nanEqual,
+	}
+
+	result := make(chan attempt, 1)
+	go func() {
+		defer func() {
+			tb.RunCleanups()
+			result <- attempt{passed: !tb.Failed() && !tb.Skipped(), messages: is.Output()}
+		}()
+		if p := catchPanic(func() { cond(is) }); p.panicked {
+			is.context.log(fmt.Sprintf("Unexpected panic: %v\n\t    At: %s", p.value, p.frame))
+			tb.Fail()
+		}
+	}()
+	return result
+}
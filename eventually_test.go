@@ -0,0 +1,109 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xdg/testy"
+)
+
+func TestEventually(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+	rec := &recordingReporter{}
+	defer testy.AddReporter(rec)()
+
+	var calls int32
+	countTo := func(n int32) func(is *testy.T) {
+		return func(is *testy.T) {
+			is.Require().True(atomic.AddInt32(&calls, 1) >= n)
+		}
+	}
+
+	// not failures
+	test.Eventually(countTo(3), time.Second, time.Millisecond)
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+	test.Consistently(func(is *testy.T) { is.Equal(1, 1) }, 10*time.Millisecond, time.Millisecond)
+
+	// failures
+	calls = 0
+	test.Label("Polling").Eventually(func(is *testy.T) {
+		is.Equal(atomic.AddInt32(&calls, 1), int32(0))
+		is.Log("still waiting")
+	}, 20*time.Millisecond, time.Millisecond)
+	test.Eventually(func(is *testy.T) { time.Sleep(time.Second) }, 10*time.Millisecond, time.Millisecond)
+	test.Eventually(func(is *testy.T) { is.FailNow() }, 5*time.Millisecond, time.Millisecond)
+	test.Consistently(func(is *testy.T) { panic("boom") }, time.Second, time.Millisecond)
+	calls = 0
+	test.Consistently(func(is *testy.T) {
+		is.Label("Call").True(atomic.AddInt32(&calls, 1) < 3)
+	}, time.Second, time.Millisecond)
+
+	if fc := test.FailCount(); fc != 5 {
+		t.Fatalf("Incorrect FailCount. Got %d, but expected 5", fc)
+	}
+
+	output := test.Output()
+	expect := []string{
+		`(?s)eventually_test.go:\d+: Polling: Condition was not met within 20ms after \d+ attempts; last attempt:\n\s+eventually_test.go:41: Values were not equal:\n\s+Got: \d+ \(int32\)\n\s+Wanted: 0 \(int32\)\n\s+eventually_test.go:42: still waiting$`,
+		`Condition was not met within 10ms; attempt 1 was still running. Previous attempt:\n\s+\(no attempt finished\)$`,
+		`Condition was not met within 5ms after \d+ attempts; last attempt:\n\s+\(failed without a message\)$`,
+		`(?s)Condition did not hold for 1s; attempt 1 failed:\n\s+Unexpected panic: boom\n\s+At: eventually_test.go:\d+ `,
+		`(?s)Condition did not hold for 1s; attempt 3 failed:\n\s+eventually_test.go:49: Call: Expression was not true: atomic.AddInt32\(&calls, 1\) < 3$`,
+	}
+	for i, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[i]); !ok {
+			t.Errorf("Output %d didn't match '%s': '%s'", i, e, output[i])
+		}
+	}
+
+	// Only the outer failures reach registered reporters.
+	rec.Lock()
+	defer rec.Unlock()
+	if len(rec.events) != 5 {
+		t.Errorf("Expected 5 reported events, got %d: %v", len(rec.events), rec.events)
+	}
+}
+
+func TestConsistentlyBlocked(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	release := make(chan struct{})
+	defer close(release)
+	calls := int32(0)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		test.Consistently(func(is *testy.T) {
+			if atomic.AddInt32(&calls, 1) == 2 {
+				<-release
+			}
+		}, 20*time.Millisecond, time.Millisecond)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Consistently didn't return while a call was blocked")
+	}
+
+	output := test.Output()
+	if len(output) != 1 {
+		t.Fatalf("Expected 1 failure, got %d: %v", len(output), output)
+	}
+	e := `eventually_test.go:\d+: Condition did not hold for 20ms; attempt 2 was still running$`
+	if ok, _ := regexp.MatchString(e, output[0]); !ok {
+		t.Errorf("Output didn't match '%s': '%s'", e, output[0])
+	}
+}
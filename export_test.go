@@ -0,0 +1,39 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+// RewriteInline exposes the snapshot source rewriter to tests, which can't
+// run the update mode of MatchInline without rewriting their own source.
+var RewriteInline = rewriteInline
+
+// FormatPath exposes file name rendering to tests, which can't control
+// where their own source files are.
+var FormatPath = formatPath
+
+// LineEdits returns the operations of the line edit script between a and
+// b as a string of ' ', '-' and '+', and whether the positions recorded in
+// the script are consistent with them.
+func LineEdits(a, b []string) (string, bool) {
+	ops := make([]byte, 0, len(a)+len(b))
+	i, j := 0, 0
+	ok := true
+	for _, e := range myers(a, b) {
+		ops = append(ops, e.op)
+		ok = ok && e.got == i && e.want == j
+		switch e.op {
+		case ' ':
+			ok = ok && i < len(a) && j < len(b) && a[i] == b[j]
+			i++
+			j++
+		case '-':
+			i++
+		case '+':
+			j++
+		}
+	}
+	return string(ops), ok && i == len(a) && j == len(b)
+}
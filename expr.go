@@ -0,0 +1,218 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"strings"
+	"sync"
+)
+
+// sourceFile is a parsed Go source file, cached so that a file with many
+// failing assertions is only parsed once.
+type sourceFile struct {
+	fset *token.FileSet
+	ast  *ast.File
+	src  []byte
+
+	typesOnce sync.Once
+	types     map[ast.Expr]types.TypeAndValue
+}
+
+var sourceCache = struct {
+	sync.Mutex
+	files map[string]*sourceFile
+}{files: make(map[string]*sourceFile)}
+
+// parseSource returns the parsed source file at path or nil if it can't be
+// read or parsed.
+func parseSource(path string) *sourceFile {
+	sourceCache.Lock()
+	defer sourceCache.Unlock()
+	if f, ok := sourceCache.files[path]; ok {
+		return f
+	}
+
+	var f *sourceFile
+	if src, err := os.ReadFile(path); err == nil {
+		fset := token.NewFileSet()
+		if file, err := parser.ParseFile(fset, path, src, 0); err == nil {
+			f = &sourceFile{fset: fset, ast: file, src: src}
+		}
+	}
+	sourceCache.files[path] = f
+	return f
+}
+
+// findCall returns the call of a method with the given name that spans the
+// given line of a source file.  Since runtime frames have no column, it
+// returns nil if more than one such call spans the line, as for
+// 'is.True(a); is.True(b)', rather than guess which one was made.
+func (f *sourceFile) findCall(line int, method string) *ast.CallExpr {
+	var found *ast.CallExpr
+	matches := 0
+	ast.Inspect(f.ast, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if f.fset.Position(call.Pos()).Line > line || f.fset.Position(call.End()).Line < line {
+			return true
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == method {
+			found = call
+			matches++
+		}
+		return true
+	})
+	if matches != 1 {
+		return nil
+	}
+	return found
+}
+
+// text returns the source text of a node with runs of white space,
+// including line breaks, collapsed to a single space.
+func (f *sourceFile) text(n ast.Node) string {
+	start := f.fset.Position(n.Pos()).Offset
+	end := f.fset.Position(n.End()).Offset
+	return strings.Join(strings.Fields(string(f.src[start:end])), " ")
+}
+
+// typeInfo returns the types and constant values of the expressions in the
+// file.  Only the file itself is checked, so identifiers from other files
+// and imported packages have no type, but constants declared in the file
+// and literals are known.
+func (f *sourceFile) typeInfo() map[ast.Expr]types.TypeAndValue {
+	f.typesOnce.Do(func() {
+		info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+		conf := types.Config{Error: func(error) {}}
+		conf.Check(f.ast.Name.Name, f.fset, []*ast.File{f.ast}, info)
+		f.types = info.Types
+	})
+	return f.types
+}
+
+// exprValue is the source text of a sub-expression and its value.
+type exprValue struct {
+	expr  string
+	value string
+}
+
+// sourceExpr returns the source text of the single argument passed to a
+// call of 'method' on the given line, e.g. "len(items) == want" for
+// 'is.True(len(items) == want)'.  It returns an empty string if the source
+// isn't available or the line has no single such call, as when a helper
+// reports a failure with Uplevel.
+//
+// It also returns the values of the sub-expressions of the argument that
+// are known without running the code again: constants, and any values
+// implied by 'result', the value the whole argument had.  For example, if
+// 'n == limit' was true and 'limit' is a constant 4, then 'n' was 4; if
+// '!done && ok' was true, 'done' was false and 'ok' true.  The values of
+// variables are not shown otherwise, since Go can't read them back from
+// the caller's frame.
+func sourceExpr(path string, line int, method string, result constant.Value) (string, []exprValue) {
+	f := parseSource(path)
+	if f == nil {
+		return "", nil
+	}
+	call := f.findCall(line, method)
+	if call == nil || len(call.Args) != 1 {
+		return "", nil
+	}
+	arg := call.Args[0]
+
+	known := make(map[ast.Expr]constant.Value)
+	info := f.typeInfo()
+	ast.Inspect(arg, func(n ast.Node) bool {
+		if e, ok := n.(ast.Expr); ok && info[e].Value != nil {
+			known[e] = info[e].Value
+		}
+		return true
+	})
+	deduce(known, arg, result)
+
+	var values []exprValue
+	seen := make(map[string]bool)
+	ast.Inspect(arg, func(n ast.Node) bool {
+		e, ok := n.(ast.Expr)
+		if !ok || e == arg || known[e] == nil {
+			return true
+		}
+		switch e.(type) {
+		case *ast.BasicLit, *ast.ParenExpr:
+			return true
+		}
+		text, value := f.text(e), known[e].ExactString()
+		if text != value && !seen[text] {
+			seen[text] = true
+			values = append(values, exprValue{text, value})
+		}
+		return true
+	})
+	return f.text(arg), values
+}
+
+// deduce records that expression e had value v, and what that implies for
+// the values of its operands.
+func deduce(known map[ast.Expr]constant.Value, e ast.Expr, v constant.Value) {
+	if v == nil {
+		return
+	}
+	if known[e] == nil {
+		known[e] = v
+	}
+	isBool := v.Kind() == constant.Bool
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		deduce(known, e.X, v)
+	case *ast.UnaryExpr:
+		if e.Op == token.NOT && isBool {
+			deduce(known, e.X, constant.UnaryOp(token.NOT, v, 0))
+		}
+	case *ast.BinaryExpr:
+		if !isBool {
+			return
+		}
+		b := constant.BoolVal(v)
+		switch e.Op {
+		case token.LAND, token.LOR:
+			// 'a && b' true or 'a || b' false determine both operands.
+			// Otherwise, a known operand that doesn't decide the result
+			// on its own, such as a true 'a' in a false 'a && b',
+			// determines the other.
+			if b == (e.Op == token.LAND) {
+				deduce(known, e.X, v)
+				deduce(known, e.Y, v)
+			} else if x := known[e.X]; x != nil && constant.BoolVal(x) != b {
+				deduce(known, e.Y, v)
+			} else if y := known[e.Y]; y != nil && constant.BoolVal(y) != b {
+				deduce(known, e.X, v)
+			}
+		case token.EQL, token.NEQ:
+			// Equal operands take each other's values; unequal booleans
+			// take the opposite.
+			equal := b == (e.Op == token.EQL)
+			for _, pair := range [][2]ast.Expr{{e.X, e.Y}, {e.Y, e.X}} {
+				other := known[pair[1]]
+				switch {
+				case other == nil:
+				case equal:
+					deduce(known, pair[0], other)
+				case other.Kind() == constant.Bool:
+					deduce(known, pair[0], constant.UnaryOp(token.NOT, other, 0))
+				}
+			}
+		}
+	}
+}
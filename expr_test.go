@@ -0,0 +1,76 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+func TestExpressions(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	items := []int{1, 2, 3}
+	want := 4
+	var aNil *int
+	done := true
+	const limit = 4
+	const ready, verbose = false, true
+	x := 5
+
+	test.True(len(items) == want)
+	test.Label("Multi").False(
+		len(items) < want &&
+			items[0] == 1,
+	)
+	test.Nil(errors.New("oops"))
+	test.NotNil(aNil)
+	checkPositive(test, -1)
+	test.True(len(items) == limit)
+	test.True(!done || len(items) > limit)
+	func() { test.True(true); test.True(len(items) > 3) }()
+	test.True(ready && x > 3)
+	test.False(verbose || x > 3)
+
+	output := test.Output()
+	if len(output) != 10 {
+		t.Fatalf("Expected 10 failures, got %d: %v", len(output), output)
+	}
+
+	expect := []string{
+		`expr_test.go:\d+: Expression was not true: len\(items\) == want$`,
+		`expr_test.go:\d+: Multi: Expression was not false: len\(items\) < want && items\[0\] == 1\n` +
+			`\s+len\(items\) < want: true\n` +
+			`\s+items\[0\] == 1: true\n` +
+			`\s+items\[0\]: 1$`,
+		`(?s)expr_test.go:\d+: Expression was not nil: errors\.New\("oops"\)\n\s+Got: oops \(\*errors\.errorString\)$`,
+		`expr_test.go:\d+: Expression was nil: aNil$`,
+		`expr_test.go:\d+: Expression was not true$`,
+		`expr_test.go:\d+: Expression was not true: len\(items\) == limit\n\s+limit: 4$`,
+		`expr_test.go:\d+: Expression was not true: !done \|\| len\(items\) > limit\n` +
+			`\s+!done: false\n` +
+			`\s+done: true\n` +
+			`\s+len\(items\) > limit: false\n` +
+			`\s+limit: 4$`,
+		`expr_test.go:\d+: Expression was not true$`,
+		`expr_test.go:\d+: Expression was not true: ready && x > 3\n\s+ready: false$`,
+		`expr_test.go:\d+: Expression was not false: verbose \|\| x > 3\n\s+verbose: true$`,
+	}
+	for i, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[i]); !ok {
+			t.Errorf("Output %d didn't match '%s': '%s'", i, e, output[i])
+		}
+	}
+}
+
+func checkPositive(is *testy.T, n int) {
+	is.Uplevel(1).True(n > 0)
+}
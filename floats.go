@@ -0,0 +1,184 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// InDelta checks if two numbers differ by no more than delta; if not, it
+// logs an error showing the difference.  Numbers of different types are
+// compared as float64.  Structs, maps, slices and arrays are compared like
+// Equal, except that floating point elements and fields only need to be
+// within delta of each other.  NaN is never within delta of anything
+// unless the facade comes from NaNsEqual.
+func (t *T) InDelta(got, want interface{}, delta float64) {
+	tol := &tolerance{
+		name:     fmt.Sprintf("delta %g", delta),
+		allowed:  fmt.Sprintf("%g", delta),
+		nanEqual: t.nanEqual,
+		diff: func(g, w float64, bits int) (string, bool) {
+			diff := math.Abs(g - w)
+			return fmt.Sprintf("%g", diff), diff <= delta
+		},
+	}
+	if msg, ok := tol.check(got, want); !ok {
+		t.report(Event{Kind: EventFailure, Message: msg, Got: got, Want: want})
+		t.fail()
+	}
+}
+
+// InEpsilon is like InDelta, but checks the relative difference of two
+// numbers: the absolute difference divided by the magnitude of 'want'.
+// If 'want' is zero, the absolute difference is used.
+func (t *T) InEpsilon(got, want interface{}, epsilon float64) {
+	tol := &tolerance{
+		name:     fmt.Sprintf("epsilon %g", epsilon),
+		allowed:  fmt.Sprintf("%g", epsilon),
+		nanEqual: t.nanEqual,
+		diff: func(g, w float64, bits int) (string, bool) {
+			diff := math.Abs(g - w)
+			if w != 0 {
+				diff /= math.Abs(w)
+			}
+			return fmt.Sprintf("%g relative", diff), diff <= epsilon
+		},
+	}
+	if msg, ok := tol.check(got, want); !ok {
+		t.report(Event{Kind: EventFailure, Message: msg, Got: got, Want: want})
+		t.fail()
+	}
+}
+
+// InULPs is like InDelta, but checks the number of units in the last place
+// between two numbers, i.e. how many representable floating point values
+// lie between them.  float32 values are counted in float32 steps.
+func (t *T) InULPs(got, want interface{}, ulps uint64) {
+	tol := &tolerance{
+		name:     fmt.Sprintf("%d ULPs", ulps),
+		allowed:  fmt.Sprintf("%d ULPs", ulps),
+		nanEqual: t.nanEqual,
+		diff: func(g, w float64, bits int) (string, bool) {
+			diff := ulpDistance(g, w, bits)
+			return fmt.Sprintf("%d ULPs", diff), diff <= ulps
+		},
+	}
+	if msg, ok := tol.check(got, want); !ok {
+		t.report(Event{Kind: EventFailure, Message: msg, Got: got, Want: want})
+		t.fail()
+	}
+}
+
+// NaNsEqual returns a testy.T struct whose InDelta, InEpsilon and InULPs
+// helpers treat two NaN values as equal.
+func (t T) NaNsEqual() *T {
+	t.nanEqual = true
+	return &t
+}
+
+// tolerance describes how far apart floating point numbers may be.
+type tolerance struct {
+	name     string // for the error message, e.g. "delta 0.1"
+	allowed  string // the allowed difference, e.g. "0.1"
+	nanEqual bool
+
+	// diff describes the difference between two numbers of the given bit
+	// size and reports whether it is allowed.
+	diff func(got, want float64, bits int) (string, bool)
+}
+
+// within describes the difference between two numbers and reports whether
+// it is allowed, taking care of infinities and NaN.
+func (tol *tolerance) within(got, want float64, bits int) (string, bool) {
+	if math.IsNaN(got) || math.IsNaN(want) {
+		return "NaN", math.IsNaN(got) && math.IsNaN(want) && tol.nanEqual
+	}
+	if got == want {
+		return "0", true
+	}
+	return tol.diff(got, want, bits)
+}
+
+// check compares two values within the tolerance and returns an error
+// message if they differ.
+func (tol *tolerance) check(got, want interface{}) (string, bool) {
+	g, w := reflect.ValueOf(got), reflect.ValueOf(want)
+	if isNumber(g) && isNumber(w) {
+		bits := 64
+		if g.Kind() == reflect.Float32 && w.Kind() == reflect.Float32 {
+			bits = 32
+		}
+		diff, ok := tol.within(toFloat(g), toFloat(w), bits)
+		if ok {
+			return "", true
+		}
+		return fmt.Sprintf("Values were not within %s:\n%s%s  Diff: %s (allowed %s)",
+			tol.name, diag("   Got", got), diag("Wanted", want), diff, tol.allowed), false
+	}
+
+	diffs := diffValuesWithin(got, want, tol)
+	if len(diffs) == 0 {
+		return "", true
+	}
+	return fmt.Sprintf("Values were not within %s:\n%s", tol.name, strings.Join(diffs, "\n")), false
+}
+
+func isNumber(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint())
+	}
+	return float64(v.Int())
+}
+
+// ulpDistance counts the representable floating point values of the given
+// bit size between two numbers.  The bit patterns are mapped to integers
+// that sort in the same order as the numbers they represent, so the
+// distance is their difference.
+func ulpDistance(a, b float64, bits int) uint64 {
+	var x, y int64
+	if bits == 32 {
+		x, y = orderedBits32(float32(a)), orderedBits32(float32(b))
+	} else {
+		x, y = orderedBits64(a), orderedBits64(b)
+	}
+	if x < y {
+		x, y = y, x
+	}
+	return uint64(x) - uint64(y)
+}
+
+func orderedBits64(f float64) int64 {
+	i := int64(math.Float64bits(f))
+	if i < 0 {
+		i = math.MinInt64 - i
+	}
+	return i
+}
+
+func orderedBits32(f float32) int64 {
+	i := int64(int32(math.Float32bits(f)))
+	if i < 0 {
+		i = math.MinInt32 - i
+	}
+	return i
+}
@@ -0,0 +1,71 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"math"
+	"regexp"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+type point struct {
+	X, Y  float64
+	Label string
+}
+
+func TestFloatTolerance(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+	nan := math.NaN()
+
+	// not failures
+	test.InDelta(0.1+0.2, 0.3, 1e-9)
+	test.InDelta(1, 1.05, 0.1)
+	test.InDelta(math.Inf(1), math.Inf(1), 0.1)
+	test.InEpsilon(101.0, 100.0, 0.02)
+	test.InEpsilon(0.0, 0.0, 0.01)
+	test.InULPs(0.1+0.2, 0.3, 1)
+	test.InULPs(float32(1), math.Nextafter32(1, 2), 1)
+	test.InULPs(0.0, math.Copysign(0, -1), 0)
+	test.InDelta([]float64{1, 2}, []float64{1.01, 2.01}, 0.1)
+	test.InDelta(point{1, 2, "a"}, point{1.001, 2.001, "a"}, 0.01)
+	test.NaNsEqual().InDelta(nan, nan, 0.1)
+	test.NaNsEqual().InDelta(map[string]float64{"x": nan}, map[string]float64{"x": nan}, 0.1)
+
+	// failures
+	test.InDelta(0.5, 0.3, 0.1)
+	test.InEpsilon(110.0, 100.0, 0.05)
+	test.InULPs(1.0, math.Nextafter(math.Nextafter(1, 2), 2), 1)
+	test.InDelta(nan, nan, 0.1)
+	test.InDelta([]float64{1, 2, 3}, []float64{1, 2.5, 3}, 0.1)
+	test.InDelta(point{1, 2, "a"}, point{1, 2, "b"}, 0.1)
+	test.InDelta(map[string]float64{"x": 1}, map[string]float64{"x": 1.5}, 0.1)
+	test.InDelta("foo", 1.0, 0.1)
+
+	output := test.Output()
+	if len(output) != 8 {
+		t.Fatalf("Expected 8 failures, got %d: %v", len(output), output)
+	}
+
+	expect := []string{
+		`(?s)floats_test.go:\d+: Values were not within delta 0.1:\n\s+Got: 0.5 \(float64\)\n\s+Wanted: 0.3 \(float64\)\n\s+Diff: 0.2 \(allowed 0.1\)$`,
+		`(?s)Values were not within epsilon 0.05:.*Diff: 0.1 relative \(allowed 0.05\)$`,
+		`(?s)Values were not within 1 ULPs:.*Diff: 2 ULPs \(allowed 1 ULPs\)$`,
+		`(?s)Values were not within delta 0.1:.*Diff: NaN \(allowed 0.1\)$`,
+		`(?s)Values were not within delta 0.1:\n\s+\[1\]: got 2, want 2.5, difference 0.5$`,
+		`(?s)\.Label: got "a", want "b"$`,
+		`(?s)\["x"\]: got 1, want 1.5, difference 0.5$`,
+		`(?s)got "foo" \(string\), want 1 \(float64\)$`,
+	}
+	for i, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[i]); !ok {
+			t.Errorf("Output %d didn't match '%s': '%s'", i, e, output[i])
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Eq checks if its arguments are equal using the == operator.  Unlike the
+// Equal method, both arguments must have the same type, so comparing an
+// int to an int64 is a compile error rather than a test failure.  If the
+// values are not equal, an error is logged through 'is', with its label
+// and call depth, and the 'got' and 'want' values are logged on subsequent
+// lines for comparison.
+//
+// An interface type such as 'any' satisfies 'comparable', but == panics if
+// the dynamic values, such as slices, can't be compared.  Eq and the other
+// generic functions log an error instead.
+func Eq[V comparable](is *T, got, want V) {
+	equal, err := tryEqual(func() bool { return got == want })
+	if err != nil {
+		is.report(uncomparable(err, got, want))
+		is.fail()
+	} else if !equal {
+		is.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Values were not equal:\n%s", diffDiag(got, want)),
+			Got:     got,
+			Want:    want,
+		})
+		is.fail()
+	}
+}
+
+// NotEq inverts the logic of Eq but is otherwise similar.
+func NotEq[V comparable](is *T, got, want V) {
+	equal, err := tryEqual(func() bool { return got == want })
+	if err != nil {
+		is.report(uncomparable(err, got, want))
+		is.fail()
+	} else if equal {
+		is.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Values were not unequal:\n%s", diag("  Both", got)),
+			Got:     got,
+			Want:    want,
+		})
+		is.fail()
+	}
+}
+
+// SliceEq checks if two slices of the same type have the same length and
+// equal elements using the == operator.  A nil slice equals an empty one.
+// If not, an error is logged through 'is' showing the elements that
+// differ.
+func SliceEq[S ~[]E, E comparable](is *T, got, want S) {
+	equal, err := tryEqual(func() bool { return sliceEqual(got, want) })
+	if err != nil {
+		is.report(uncomparable(err, got, want))
+		is.fail()
+	} else if !equal {
+		is.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Slices were not equal:\n%s", diffDiag(got, want)),
+			Got:     got,
+			Want:    want,
+		})
+		is.fail()
+	}
+}
+
+// MapEq checks if two maps of the same type have the same keys and equal
+// values using the == operator.  A nil map equals an empty one.  If not,
+// an error is logged through 'is' showing the keys that differ.
+func MapEq[M ~map[K]V, K, V comparable](is *T, got, want M) {
+	equal, err := tryEqual(func() bool { return mapEqual(got, want) })
+	if err != nil {
+		is.report(uncomparable(err, got, want))
+		is.fail()
+	} else if !equal {
+		is.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Maps were not equal:\n%s", diffDiag(got, want)),
+			Got:     got,
+			Want:    want,
+		})
+		is.fail()
+	}
+}
+
+func sliceEqual[E comparable](got, want []E) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func mapEqual[K, V comparable](got, want map[K]V) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for k, g := range got {
+		if w, ok := want[k]; !ok || g != w {
+			return false
+		}
+	}
+	return true
+}
+
+// tryEqual calls an equality check and returns the runtime error if it
+// panics comparing uncomparable values held in interfaces.
+func tryEqual(f func() bool) (equal bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			rerr, ok := r.(runtime.Error)
+			if !ok {
+				panic(r)
+			}
+			err = rerr
+		}
+	}()
+	return f(), nil
+}
+
+// uncomparable returns the failure event for values that == can't compare.
+func uncomparable(err error, got, want interface{}) Event {
+	return Event{
+		Kind:    EventFailure,
+		Message: fmt.Sprintf("Values could not be compared: %v\n%s%s", err, diag("   Got", got), diag("Wanted", want)),
+		Got:     got,
+		Want:    want,
+	}
+}
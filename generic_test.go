@@ -0,0 +1,90 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+type ids []int
+
+func eqHelper(is *testy.T, got, want string) {
+	testy.Eq(is.Uplevel(1), got, want)
+}
+
+func TestGenericHelpers(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	// not failures
+	testy.Eq(test, 1, 1)
+	testy.Eq(test, "foo", "foo")
+	testy.NotEq(test, 1.0, 2.0)
+	testy.SliceEq(test, ids{1, 2}, ids{1, 2})
+	testy.SliceEq(test, nil, []string{})
+	testy.MapEq(test, map[string]int{"a": 1}, map[string]int{"a": 1})
+	testy.MapEq(test, nil, map[int]bool{})
+
+	// failures
+	testy.Eq(test, int64(1), 2)
+	testy.Eq(test.Label("Name"), "foo\tbar", "foo\tbaz")
+	testy.NotEq(test, 'x', 'x')
+	testy.SliceEq(test, ids{1, 2, 3}, ids{1, 4})
+	testy.MapEq(test, map[string]int{"a": 1, "b": 2}, map[string]int{"a": 1, "c": 2})
+	eqHelper(test, "foo", "bar")
+
+	if fc := test.FailCount(); fc != 6 {
+		t.Fatalf("Incorrect FailCount. Got %d, but expected 6", fc)
+	}
+
+	output := test.Output()
+	expect := []string{
+		`(?s)generic_test.go:36: Values were not equal:\n\s+Got: 1 \(int64\)\n\s+Wanted: 2 \(int64\)$`,
+		`(?s)generic_test.go:37: Name: Values were not equal:\n\s+Got: "foo\\tbar"\n\s+Wanted: "foo\\tbaz"$`,
+		`(?s)generic_test.go:38: Values were not unequal:\n\s+Both: 120 \(int32\)$`,
+		`(?s)generic_test.go:39: Slices were not equal:\n\s+got length 3, want length 2\n\s+\[1\]: got 2, want 4\n\s+\[2\]: got 3, want <none>$`,
+		`(?s)generic_test.go:40: Maps were not equal:\n\s+\["b"\]: got 2, want <none>\n\s+\["c"\]: got <none>, want 2$`,
+		`(?s)generic_test.go:41: Values were not equal:`,
+	}
+	for i, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[i]); !ok {
+			t.Errorf("Output %d didn't match '%s': '%s'", i, e, output[i])
+		}
+	}
+}
+
+func TestGenericUncomparable(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	testy.Eq[any](test, []int{1}, []int{1})
+	testy.NotEq[any](test, map[string]int{}, map[string]int{})
+	testy.SliceEq(test, []any{1, []int{2}}, []any{1, []int{2}})
+	testy.MapEq(test, map[string]any{"a": []int{1}}, map[string]any{"a": []int{1}})
+	testy.Eq[any](test, 1, "1")
+
+	if fc := test.FailCount(); fc != 5 {
+		t.Fatalf("Incorrect FailCount. Got %d, but expected 5", fc)
+	}
+
+	output := test.Output()
+	expect := []string{
+		`(?s)Values could not be compared: runtime error: comparing uncomparable type \[\]int\n\s+Got: \[1\] \(\[\]int\)\n\s+Wanted: \[1\] \(\[\]int\)$`,
+		`(?s)Values could not be compared: runtime error: comparing uncomparable type map\[string\]int`,
+		`(?s)Values could not be compared: runtime error: comparing uncomparable type \[\]int`,
+		`(?s)Values could not be compared: runtime error: comparing uncomparable type \[\]int`,
+		`(?s)Values were not equal:`,
+	}
+	for i, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[i]); !ok {
+			t.Errorf("Output %d didn't match '%s': '%s'", i, e, output[i])
+		}
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/xdg/testy
+
+go 1.20
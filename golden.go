@@ -0,0 +1,104 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// update is set by running tests with 'go test -args -testy.update'.
+var update = flag.Bool("testy.update", false, "rewrite golden files and inline snapshots with the output of the tests")
+
+// Golden checks if 'got' matches the contents of the golden file
+// 'testdata/<case name>/<name>.golden', relative to the directory the test
+// runs in, which is the package directory under 'go test'.  The case name
+// is the one given to NewCase, or the full name of the test for New, such
+// as "TestParse/empty_input", so facades from New in subtest closures
+// don't share a directory.  Subtests get nested directories, as do
+// slashes in 'name'.  In both names, characters that aren't safe in file
+// names are replaced with '_', and so are "." and ".." elements, so the
+// file is always inside testdata.  If the contents differ, it logs an
+// error with a line diff of the contents and the file on subsequent lines.
+//
+// When the tests run with 'go test -args -testy.update', Golden writes
+// 'got' to the golden file instead, creating it if needed.
+func (t *T) Golden(name string, got []byte) {
+	path := t.goldenPath(name)
+
+	if *update {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = os.WriteFile(path, got, 0644)
+		}
+		if err != nil {
+			t.report(Event{Kind: EventFailure, Message: fmt.Sprintf("Can't update golden file: %v", err)})
+			t.fail()
+			return
+		}
+		t.report(Event{Kind: EventLog, Message: fmt.Sprintf("Updated golden file %s", path)})
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		msg := fmt.Sprintf("Can't read golden file: %v", err)
+		if os.IsNotExist(err) {
+			msg = fmt.Sprintf("Golden file %s does not exist; run 'go test -args -testy.update' to create it", path)
+		}
+		t.report(Event{Kind: EventFailure, Message: msg, Got: got})
+		t.fail()
+		return
+	}
+
+	if !bytes.Equal(got, want) {
+		t.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Output did not match golden file %s:\n%s", path, lineDiff(string(got), string(want))),
+			Got:     got,
+			Want:    want,
+		})
+		t.fail()
+	}
+}
+
+func (t *T) goldenPath(name string) string {
+	dir := t.caseName
+	if t.autoName && t.test.Name() != "" {
+		dir = t.test.Name()
+	}
+	return filepath.Join("testdata", safePath(dir), safePath(name)+".golden")
+}
+
+// safePath turns a slash-separated name into a relative file path,
+// replacing characters other than letters, digits and "-_.+=," with '_'.
+// Empty, "." and ".." elements become underscores, so the path stays
+// inside testdata.
+func safePath(name string) string {
+	parts := strings.Split(name, "/")
+	for i, p := range parts {
+		p = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_.+=,", r) {
+				return r
+			}
+			return '_'
+		}, p)
+		if strings.Trim(p, ".") == "" {
+			p = strings.Repeat("_", len(p))
+		}
+		if p == "" {
+			p = "_"
+		}
+		parts[i] = p
+	}
+	return filepath.Join(parts...)
+}
@@ -0,0 +1,108 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/xdg/testy"
+	"github.com/xdg/testy/testytest"
+)
+
+// inTempDir runs f with the working directory set to a new temporary
+// directory and the -testy.update flag set to 'update'.
+func inTempDir(t *testing.T, update string, f func()) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if err := flag.Set("testy.update", update); err != nil {
+		t.Fatal(err)
+	}
+	defer flag.Set("testy.update", "false")
+	f()
+}
+
+func TestGolden(t *testing.T) {
+	inTempDir(t, "true", func() {
+		mock := &testing.T{}
+		test := testy.NewCase(mock, "Report")
+		test.Golden("summary", []byte("one\ntwo\nthree\n"))
+
+		got, err := os.ReadFile(filepath.Join("testdata", "Report", "summary.golden"))
+		if err != nil {
+			t.Fatalf("Golden() in update mode didn't write file: %v", err)
+		}
+		if string(got) != "one\ntwo\nthree\n" {
+			t.Errorf("Golden() in update mode wrote %q", got)
+		}
+		if test.FailCount() != 0 {
+			t.Errorf("Golden() in update mode failed: %v", test.Output())
+		}
+
+		flag.Set("testy.update", "false")
+
+		// not failures
+		test.Golden("summary", []byte("one\ntwo\nthree\n"))
+
+		// failures
+		test.Golden("summary", []byte("one\n2\nthree\n"))
+		test.Golden("missing", []byte("anything"))
+
+		if fc := test.FailCount(); fc != 2 {
+			t.Fatalf("Incorrect FailCount. Got %d, but expected 2", fc)
+		}
+
+		output := test.Output()
+		expect := []string{
+			`golden_test.go:\d+: Updated golden file testdata/Report/summary.golden$`,
+			`(?s)golden_test.go:\d+: Output did not match golden file testdata/Report/summary.golden:\n\s+--- Got\n\s+\+\+\+ Wanted\n\s+@@ -1,4 \+1,4 @@\n\s+one\n\s+-\[-2-\]\n\s+\+\{\+two\+\}\n\s+three$`,
+			`golden_test.go:\d+: Golden file testdata/Report/missing.golden does not exist; run 'go test -args -testy.update' to create it$`,
+		}
+		for i, e := range expect {
+			if ok, _ := regexp.MatchString(e, output[i]); !ok {
+				t.Errorf("Output %d didn't match '%s': '%s'", i, e, output[i])
+			}
+		}
+	})
+}
+
+func TestGoldenTestNames(t *testing.T) {
+	inTempDir(t, "true", func() {
+		// Facades from New get the same case name from this function, so
+		// the directories come from the test names instead.
+		for _, name := range []string{"TestA/one", "TestB/one", "TestC/a:b/..", "TestD/with_space"} {
+			testy.New(testytest.NewRecorder(name)).Golden("out", []byte(name))
+		}
+		testy.NewCase(testytest.NewRecorder("TestE/one"), "Explicit").Golden("out", []byte("explicit"))
+		testy.NewCase(testytest.NewRecorder("TestF"), "Escape").Golden("../../x", []byte("escape"))
+
+		expect := map[string]string{
+			"TestA/one/out.golden":        "TestA/one",
+			"TestB/one/out.golden":        "TestB/one",
+			"TestC/a_b/__/out.golden":     "TestC/a:b/..",
+			"TestD/with_space/out.golden": "TestD/with_space",
+			"Explicit/out.golden":         "explicit",
+			"Escape/__/__/x.golden":       "escape",
+		}
+		for path, want := range expect {
+			got, err := os.ReadFile(filepath.Join("testdata", filepath.FromSlash(path)))
+			if err != nil || string(got) != want {
+				t.Errorf("Golden file %s had %q, not %q: %v", path, got, want, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,78 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"regexp"
+	"runtime"
+	"sync"
+)
+
+// Helper marks the calling function as a test helper, like the testing.T
+// Helper method.  When a facade reports a failure or log message, it skips
+// the frames of helper functions to find the reported line, so a helper
+// doesn't need to count frames for Uplevel and keeps working when it is
+// split into several functions.  Helpers marked on a facade apply to every
+// facade of the test case and of its subtests.
+func (t *T) Helper() {
+	pc, _, _, ok := runtime.Caller(1)
+	if !ok {
+		return
+	}
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		t.context.addHelper(fn.Name())
+	}
+}
+
+type helperEntry struct {
+	re *regexp.Regexp
+}
+
+var helperPatterns struct {
+	sync.RWMutex
+	list []*helperEntry
+}
+
+// RegisterHelpers marks every function whose fully qualified name matches
+// a regular expression as a test helper for all facades, as if each one
+// called Helper.  Names look like "example.com/pkg.Func" for functions and
+// "example.com/pkg.(*Type).Method" for methods, so a whole package of
+// fixtures can be marked with an expression like `^example\.com/fixtures\.`.
+// It returns a function that removes the registration again.
+func RegisterHelpers(re *regexp.Regexp) (remove func()) {
+	entry := &helperEntry{re}
+	helperPatterns.Lock()
+	defer helperPatterns.Unlock()
+	helperPatterns.list = append(helperPatterns.list, entry)
+	return func() {
+		helperPatterns.Lock()
+		defer helperPatterns.Unlock()
+		for i, e := range helperPatterns.list {
+			if e == entry {
+				helperPatterns.list = append(helperPatterns.list[:i:i], helperPatterns.list[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func isRegisteredHelper(name string) bool {
+	helperPatterns.RLock()
+	defer helperPatterns.RUnlock()
+	for _, e := range helperPatterns.list {
+		if e.re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHelper reports whether the named function was marked as a helper with
+// Helper or RegisterHelpers.
+func (t *T) isHelper(name string) bool {
+	return t.context.isHelper(name) || isRegisteredHelper(name)
+}
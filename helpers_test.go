@@ -0,0 +1,56 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+func assertPositive(is *testy.T, n int) {
+	is.Helper()
+	checkSign(is, n)
+}
+
+func checkSign(is *testy.T, n int) {
+	is.Helper()
+	if n <= 0 {
+		is.Errorf("%d was not positive", n)
+	}
+}
+
+func fixtureCheck(is *testy.T) {
+	is.Error("fixture failed")
+}
+
+func TestHelper(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	assertPositive(test, -1) // Line 36
+	fixtureCheck(test)       // Line 37
+
+	remove := testy.RegisterHelpers(regexp.MustCompile(`\.fixtureCheck$`))
+	fixtureCheck(test) // Line 40
+	remove()
+	fixtureCheck(test) // Line 42
+
+	output := test.Output()
+	expect := []string{
+		`helpers_test.go:36: -1 was not positive$`,
+		`helpers_test.go:29: fixture failed$`,
+		`helpers_test.go:40: fixture failed$`,
+		`helpers_test.go:29: fixture failed$`,
+	}
+	for i, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[i]); !ok {
+			t.Errorf("Output %d didn't match '%s': '%s'", i, e, output[i])
+		}
+	}
+}
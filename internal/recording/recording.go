@@ -0,0 +1,176 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package recording provides the testing.TB stand-in shared by the scratch
+// facades of testy's polling helpers and by testytest.Recorder.
+package recording
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// TB is a testing.TB that records what a test does instead of reporting
+// it.  FailNow and SkipNow end the goroutine they are called in, as they do
+// for a testing.T.  Methods that TB doesn't implement, like TempDir, go to
+// the parent test given to New, and panic if there is none.
+type TB struct {
+	testing.TB
+	name     string
+	scratch  bool
+	mutex    sync.Mutex
+	failed   bool
+	skipped  bool
+	logs     []string
+	cleanups []func()
+}
+
+// New returns a TB whose Name method returns 'name', or the name of
+// 'parent' if 'name' is empty.  'parent' may be nil.
+func New(parent testing.TB, name string) *TB {
+	return &TB{TB: parent, name: name}
+}
+
+// NewScratch is like New, but facades made around the TB, or around a
+// type that embeds it, keep their events to themselves instead of sending
+// them to registered reporters.
+func NewScratch(parent testing.TB, name string) *TB {
+	return &TB{TB: parent, name: name, scratch: true}
+}
+
+// IsScratch reports whether t is, or embeds, a TB from NewScratch.
+func IsScratch(t testing.TB) bool {
+	r, ok := t.(interface{ recording() *TB })
+	return ok && r.recording().scratch
+}
+
+func (r *TB) recording() *TB {
+	return r
+}
+
+// Name returns the name given to New, or the parent's name.
+func (r *TB) Name() string {
+	if r.name == "" && r.TB != nil {
+		return r.TB.Name()
+	}
+	return r.name
+}
+
+// Fail marks the TB as having failed.
+func (r *TB) Fail() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.failed = true
+}
+
+// FailNow marks the TB as having failed and stops the goroutine.
+func (r *TB) FailNow() {
+	r.Fail()
+	runtime.Goexit()
+}
+
+// Failed reports whether the TB has been marked as having failed.
+func (r *TB) Failed() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.failed
+}
+
+// SkipNow marks the TB as skipped and stops the goroutine.
+func (r *TB) SkipNow() {
+	r.mutex.Lock()
+	r.skipped = true
+	r.mutex.Unlock()
+	runtime.Goexit()
+}
+
+// Skipped reports whether the TB was skipped.
+func (r *TB) Skipped() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.skipped
+}
+
+// Log records its arguments, formatted like fmt.Sprintln.
+func (r *TB) Log(args ...interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.logs = append(r.logs, fmt.Sprintln(args...))
+}
+
+// Logf records its arguments, formatted like fmt.Sprintf.
+func (r *TB) Logf(format string, args ...interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.logs = append(r.logs, fmt.Sprintf(format, args...))
+}
+
+// Error is equivalent to Log followed by Fail.
+func (r *TB) Error(args ...interface{}) {
+	r.Log(args...)
+	r.Fail()
+}
+
+// Errorf is equivalent to Logf followed by Fail.
+func (r *TB) Errorf(format string, args ...interface{}) {
+	r.Logf(format, args...)
+	r.Fail()
+}
+
+// Fatal is equivalent to Log followed by FailNow.
+func (r *TB) Fatal(args ...interface{}) {
+	r.Log(args...)
+	r.FailNow()
+}
+
+// Fatalf is equivalent to Logf followed by FailNow.
+func (r *TB) Fatalf(format string, args ...interface{}) {
+	r.Logf(format, args...)
+	r.FailNow()
+}
+
+// Skip is equivalent to Log followed by SkipNow.
+func (r *TB) Skip(args ...interface{}) {
+	r.Log(args...)
+	r.SkipNow()
+}
+
+// Skipf is equivalent to Logf followed by SkipNow.
+func (r *TB) Skipf(format string, args ...interface{}) {
+	r.Logf(format, args...)
+	r.SkipNow()
+}
+
+// Helper does nothing; locations are tracked by the testy.T facade.
+func (r *TB) Helper() {}
+
+// Cleanup registers a function to be called by RunCleanups.
+func (r *TB) Cleanup(f func()) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.cleanups = append(r.cleanups, f)
+}
+
+// RunCleanups calls the functions registered with Cleanup in last added,
+// first called order.
+func (r *TB) RunCleanups() {
+	r.mutex.Lock()
+	cleanups := r.cleanups
+	r.cleanups = nil
+	r.mutex.Unlock()
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
+
+// Logs returns a copy of the messages logged directly to the TB.
+func (r *TB) Logs() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]string(nil), r.logs...)
+}
@@ -0,0 +1,148 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// JSONLogPrefix marks the log lines written by a JSONReporter from
+// NewJSONLogReporter.  The testyjson package uses it to find events in
+// test output.
+const JSONLogPrefix = "testy-event: "
+
+// JSONReporter is a Reporter that encodes every event as a JSON object.
+// It either writes one object per line to an io.Writer, such as a file
+// opened in TestMain, or logs each object through the event's test with
+// JSONLogPrefix, so the events are interleaved with the rest of the test
+// output and reach tools that read 'go test -json'.  The testyjson
+// package decodes both forms.
+//
+// Each object has these fields, with empty ones left out:
+//
+// 	time     RFC 3339 time the event was reported
+// 	test     name of the test, from the testing.TB Name method
+// 	case     test case name given to NewCase
+// 	kind     "start", "log", "fail" or "skip"
+// 	label    labels joined by ": "
+// 	attrs    object of attributes added with With, formatted as strings
+// 	file     full path to the source file of the reported line
+// 	line     line number
+// 	message  message text
+// 	got      the 'got' value of a comparison, formatted as a string
+// 	want     the 'want' value of a comparison, formatted as a string
+// 	stack    array of objects with function, file and line fields
+type JSONReporter struct {
+	mutex sync.Mutex
+	w     io.Writer
+	err   error
+}
+
+// NewJSONReporter returns a JSONReporter that writes to w.
+func NewJSONReporter(w io.Writer) *JSONReporter {
+	return &JSONReporter{w: w}
+}
+
+// NewJSONLogReporter returns a JSONReporter that logs each event through
+// the Log method of the test it was reported on.
+func NewJSONLogReporter() *JSONReporter {
+	return &JSONReporter{}
+}
+
+// Report encodes an event.  Write errors are kept for Err.
+func (r *JSONReporter) Report(e Event) {
+	data, err := json.Marshal(newJSONEvent(e))
+	if err != nil {
+		r.setErr(err)
+		return
+	}
+
+	if r.w == nil {
+		if e.TB != nil {
+			e.TB.Log(JSONLogPrefix + string(data))
+		}
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, err := r.w.Write(append(data, '\n')); err != nil && r.err == nil {
+		r.err = err
+	}
+}
+
+// Err returns the first error encoding or writing an event, if any.
+func (r *JSONReporter) Err() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.err
+}
+
+func (r *JSONReporter) setErr(err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+type jsonEvent struct {
+	Time    time.Time         `json:"time"`
+	Test    string            `json:"test,omitempty"`
+	Case    string            `json:"case,omitempty"`
+	Kind    string            `json:"kind"`
+	Label   string            `json:"label,omitempty"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+	File    string            `json:"file,omitempty"`
+	Line    int               `json:"line,omitempty"`
+	Message string            `json:"message,omitempty"`
+	Got     string            `json:"got,omitempty"`
+	Want    string            `json:"want,omitempty"`
+	Stack   []jsonFrame       `json:"stack,omitempty"`
+}
+
+type jsonFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+func newJSONEvent(e Event) jsonEvent {
+	je := jsonEvent{
+		Time:    time.Now(),
+		Case:    e.Case,
+		Kind:    e.Kind.String(),
+		Label:   e.Label,
+		File:    e.File,
+		Line:    e.Line,
+		Message: e.Message,
+	}
+	if e.TB != nil {
+		je.Test = e.TB.Name()
+	}
+	if len(e.Attrs) > 0 {
+		je.Attrs = make(map[string]string, len(e.Attrs))
+		for _, a := range e.Attrs {
+			je.Attrs[a.Key] = fmt.Sprintf("%v", a.Value)
+		}
+	}
+	if e.Got != nil {
+		je.Got = formatValue(reflect.ValueOf(e.Got))
+	}
+	if e.Want != nil {
+		je.Want = formatValue(reflect.ValueOf(e.Want))
+	}
+	for _, f := range e.Stack {
+		je.Stack = append(je.Stack, jsonFrame{Function: f.Function, File: f.File, Line: f.Line})
+	}
+	return je
+}
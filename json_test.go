@@ -0,0 +1,98 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xdg/testy"
+	"github.com/xdg/testy/testyjson"
+	"github.com/xdg/testy/testytest"
+)
+
+func TestJSONReporter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	r := testy.NewJSONReporter(buf)
+	remove := testy.AddReporter(r)
+
+	rec := testytest.NewRecorder("TestJSON")
+	test := testy.NewCase(rec, "JSON case")
+	test.Label("Row", 1).With("id", 7).Equal("foo", "bar") // Line 29
+	test.Log("hello")
+	remove()
+
+	if err := r.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dec := testyjson.NewDecoder(buf)
+	var events []testyjson.Event
+	for {
+		e, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		events = append(events, e)
+	}
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d: %v", len(events), events)
+	}
+
+	start := events[0]
+	if start.Kind != "start" || start.Case != "JSON case" || start.Test != "TestJSON" {
+		t.Errorf("Start event was wrong: %+v", start)
+	}
+
+	fail := events[1]
+	if fail.Kind != "fail" || fail.Label != "Row 1" || fail.Line != 29 || filepath.Base(fail.File) != "json_test.go" {
+		t.Errorf("Failure event was wrong: %+v", fail)
+	}
+	if fail.Got != `"foo"` || fail.Want != `"bar"` || !strings.HasPrefix(fail.Message, "Values were not equal:") {
+		t.Errorf("Failure event values were wrong: %+v", fail)
+	}
+	if !reflect.DeepEqual(fail.Attrs, map[string]string{"id": "7"}) {
+		t.Errorf("Failure event attributes were wrong: %v", fail.Attrs)
+	}
+	if fail.Time.IsZero() {
+		t.Errorf("Failure event had no time")
+	}
+
+	if log := events[2]; log.Kind != "log" || log.Message != "hello" || log.Got != "" {
+		t.Errorf("Log event was wrong: %+v", log)
+	}
+}
+
+func TestJSONLogReporter(t *testing.T) {
+	remove := testy.AddReporter(testy.NewJSONLogReporter())
+
+	rec := testytest.NewRecorder("TestJSONLog")
+	test := testy.NewCase(rec, "JSON log case")
+	test.Error("oops")
+	remove()
+
+	logs := rec.Logs()
+	if len(logs) != 2 {
+		t.Fatalf("Expected 2 log lines, got %d: %q", len(logs), logs)
+	}
+	for _, l := range logs {
+		if !strings.HasPrefix(l, testy.JSONLogPrefix) {
+			t.Errorf("Log line didn't have the prefix: %q", l)
+		}
+	}
+	e, ok := testyjson.ParseLine("    json.go:70: " + logs[1])
+	if !ok || e.Kind != "fail" || e.Message != "oops" || e.Test != "TestJSONLog" {
+		t.Errorf("Logged event was wrong: %+v", e)
+	}
+}
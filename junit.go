@@ -0,0 +1,165 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// JUnitReporter is a Reporter that collects every test case and failure
+// and writes them as a JUnit XML document for CI systems.  Register it
+// with AddReporter in TestMain and call WriteFile after the tests run:
+//
+// 	func TestMain(m *testing.M) {
+// 		junit := testy.NewJUnitReporter("junit.xml")
+// 		testy.AddReporter(junit)
+// 		code := m.Run()
+// 		if err := junit.WriteFile(); err != nil {
+// 			fmt.Fprintln(os.Stderr, err)
+// 		}
+// 		os.Exit(code)
+// 	}
+//
+// Each test case becomes a <testcase> element and each failure, including
+// those from Fail or FailNow, becomes a <failure> element, so failure
+// counts match FailCount.  Events are grouped by the name of their test,
+// from the testing.TB Name method, and their case name, like
+// "TestRender/header", so several NewCase facades on one test stay apart.
+// Facades from New take their case names from the calling function, which
+// may be a closure like "func1", so they are grouped by the test name
+// alone.  Events without a test name are grouped by their case name.
+type JUnitReporter struct {
+	// Name is used for the <testsuite> name and the testcase classname.  It
+	// defaults to the name of the test binary without ".test".
+	Name string
+
+	path  string
+	mutex sync.Mutex
+	cases []*junitCase
+	index map[string]*junitCase
+}
+
+// NewJUnitReporter returns a JUnitReporter that writes to the given path.
+func NewJUnitReporter(path string) *JUnitReporter {
+	name := strings.TrimSuffix(filepath.Base(os.Args[0]), ".test")
+	return &JUnitReporter{Name: name, path: path, index: make(map[string]*junitCase)}
+}
+
+// Report records an event against its test case.
+func (r *JUnitReporter) Report(e Event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	name := e.Case
+	if e.TB != nil && e.TB.Name() != "" {
+		name = e.TB.Name()
+		if !e.autoName {
+			name += "/" + e.Case
+		}
+	}
+	c, ok := r.index[name]
+	if !ok {
+		c = &junitCase{Name: name}
+		r.index[name] = c
+		r.cases = append(r.cases, c)
+	}
+
+	text := strings.TrimSpace(formatEvent(e, PathDefault))
+	switch e.Kind {
+	case EventFailure:
+		c.Failures = append(c.Failures, junitFailure{
+			Message: strings.SplitN(text, "\n", 2)[0],
+			Type:    "testy",
+			Text:    text,
+		})
+	case EventSkip:
+		c.Skipped = &junitSkipped{Message: text}
+	case EventLog:
+		c.SystemOut += text + "\n"
+	}
+}
+
+// WriteTo writes the JUnit XML document for all events reported so far.
+func (r *JUnitReporter) WriteTo(w io.Writer) (int64, error) {
+	r.mutex.Lock()
+	suite := junitSuite{Name: r.Name}
+	for _, c := range r.cases {
+		tc := *c
+		tc.Classname = r.Name
+		suite.Tests++
+		suite.Failures += len(tc.Failures)
+		if tc.Skipped != nil {
+			suite.Skipped++
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	r.mutex.Unlock()
+
+	doc := junitSuites{Tests: suite.Tests, Failures: suite.Failures, Suites: []junitSuite{suite}}
+	buf := bytes.NewBufferString(xml.Header)
+	enc := xml.NewEncoder(buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return 0, err
+	}
+	buf.WriteByte('\n')
+	return buf.WriteTo(w)
+}
+
+// WriteFile writes the JUnit XML document to the path given to
+// NewJUnitReporter, replacing any existing file.
+func (r *JUnitReporter) WriteFile() error {
+	f, err := os.Create(r.path)
+	if err != nil {
+		return fmt.Errorf("testy: can't write JUnit report: %v", err)
+	}
+	if _, err := r.WriteTo(f); err != nil {
+		f.Close()
+		return fmt.Errorf("testy: can't write JUnit report: %v", err)
+	}
+	return f.Close()
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string         `xml:"name,attr"`
+	Classname string         `xml:"classname,attr"`
+	Failures  []junitFailure `xml:"failure"`
+	Skipped   *junitSkipped  `xml:"skipped"`
+	SystemOut string         `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
@@ -0,0 +1,168 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/xdg/testy"
+	"github.com/xdg/testy/testytest"
+)
+
+type junitDoc struct {
+	Tests    int `xml:"tests,attr"`
+	Failures int `xml:"failures,attr"`
+	Suites   []struct {
+		Name     string `xml:"name,attr"`
+		Tests    int    `xml:"tests,attr"`
+		Failures int    `xml:"failures,attr"`
+		Cases    []struct {
+			Name      string `xml:"name,attr"`
+			Classname string `xml:"classname,attr"`
+			Failures  []struct {
+				Message string `xml:"message,attr"`
+				Text    string `xml:",chardata"`
+			} `xml:"failure"`
+			SystemOut string `xml:"system-out"`
+		} `xml:"testcase"`
+	} `xml:"testsuite"`
+}
+
+func TestJUnitReporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "junit.xml")
+	junit := testy.NewJUnitReporter(path)
+	junit.Name = "example"
+	remove := testy.AddReporter(junit)
+
+	passing := testy.NewCase(&testing.T{}, "Passing")
+	passing.Log("just a note")
+	passing.True(true)
+
+	failing := testy.NewCase(&testing.T{}, "Failing")
+	failing.Label("Row", 3).Equal(1, 2) // Line 51
+	failing.Fail()                      // Line 52
+
+	remove()
+
+	buf := new(bytes.Buffer)
+	if _, err := junit.WriteTo(buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+
+	var doc junitDoc
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Couldn't parse JUnit output: %v\n%s", err, buf.String())
+	}
+
+	if doc.Tests != 2 || doc.Failures != failing.FailCount() || len(doc.Suites) != 1 {
+		t.Fatalf("Wrong totals: %+v\n%s", doc, buf.String())
+	}
+	suite := doc.Suites[0]
+	if suite.Name != "example" || suite.Tests != 2 || suite.Failures != 2 || len(suite.Cases) != 2 {
+		t.Fatalf("Wrong suite: %+v", suite)
+	}
+
+	pass := suite.Cases[0]
+	if pass.Name != "Passing" || pass.Classname != "example" || len(pass.Failures) != 0 {
+		t.Errorf("Wrong passing case: %+v", pass)
+	}
+	if ok, _ := regexp.MatchString(`junit_test.go:\d+: just a note`, pass.SystemOut); !ok {
+		t.Errorf("Log missing from system-out: '%s'", pass.SystemOut)
+	}
+
+	fail := suite.Cases[1]
+	if fail.Name != "Failing" || len(fail.Failures) != 2 {
+		t.Fatalf("Wrong failing case: %+v", fail)
+	}
+	if got := fail.Failures[0].Message; got != "junit_test.go:51: Row 3: Values were not equal:" {
+		t.Errorf("Wrong failure message: '%s'", got)
+	}
+	if ok, _ := regexp.MatchString(`(?m)^\s+Wanted: 2 \(int\)$`, fail.Failures[0].Text); !ok {
+		t.Errorf("Wrong failure text: '%s'", fail.Failures[0].Text)
+	}
+	if got := fail.Failures[1].Message; got != "junit_test.go:52:" {
+		t.Errorf("Wrong failure message: '%s'", got)
+	}
+
+	if err := junit.WriteFile(); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	written, err := os.ReadFile(path)
+	if err != nil || !bytes.Equal(written, buf.Bytes()) {
+		t.Errorf("Report file not written correctly: %v\n%s", err, written)
+	}
+}
+
+func TestJUnitReporterTestNames(t *testing.T) {
+	junit := testy.NewJUnitReporter("")
+	remove := testy.AddReporter(junit)
+
+	// Both facades get the same case name from this function.
+	first := testy.New(testytest.NewRecorder("TestA/one"))
+	second := testy.New(testytest.NewRecorder("TestB/one"))
+	first.Error("first")
+	second.Error("second")
+	second.Error("again")
+
+	remove()
+
+	buf := new(bytes.Buffer)
+	if _, err := junit.WriteTo(buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	var doc junitDoc
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Couldn't parse JUnit output: %v\n%s", err, buf.String())
+	}
+
+	cases := doc.Suites[0].Cases
+	if len(cases) != 2 {
+		t.Fatalf("Expected 2 test cases, got %d:\n%s", len(cases), buf.String())
+	}
+	if cases[0].Name != "TestA/one" || len(cases[0].Failures) != 1 {
+		t.Errorf("Wrong first case: %+v", cases[0])
+	}
+	if cases[1].Name != "TestB/one" || len(cases[1].Failures) != 2 {
+		t.Errorf("Wrong second case: %+v", cases[1])
+	}
+}
+
+func TestJUnitReporterCaseNames(t *testing.T) {
+	junit := testy.NewJUnitReporter("")
+	remove := testy.AddReporter(junit)
+
+	rec := testytest.NewRecorder("TestX")
+	testy.NewCase(rec, "parse").Error("bad input")
+	testy.NewCase(rec, "render").Log("fine")
+
+	remove()
+
+	buf := new(bytes.Buffer)
+	if _, err := junit.WriteTo(buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	var doc junitDoc
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Couldn't parse JUnit output: %v\n%s", err, buf.String())
+	}
+
+	cases := doc.Suites[0].Cases
+	if len(cases) != 2 {
+		t.Fatalf("Expected 2 test cases, got %d:\n%s", len(cases), buf.String())
+	}
+	if cases[0].Name != "TestX/parse" || len(cases[0].Failures) != 1 {
+		t.Errorf("Wrong first case: %+v", cases[0])
+	}
+	if cases[1].Name != "TestX/render" || len(cases[1].Failures) != 0 {
+		t.Errorf("Wrong second case: %+v", cases[1])
+	}
+}
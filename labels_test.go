@@ -0,0 +1,56 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+func checkField(is *testy.T, name string, got, want interface{}) {
+	is.Uplevel(1).Label("field", name).Equal(got, want)
+}
+
+func TestLabelStacking(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	row := test.Label("row", 3)
+	checkField(row, "Name", "bob", "alice")
+	row.ResetLabel("only").Error("reset")
+	row.ResetLabel().Error("none")
+	row.Label().Error("empty label ignored")
+
+	// Facades made from the same parent don't share labels.
+	a := row.Label("a")
+	b := row.Label("b")
+	if got := a.Labels(); !reflect.DeepEqual(got, []string{"row 3", "a"}) {
+		t.Errorf("Labels() of a were wrong: %v", got)
+	}
+	if got := b.Labels(); !reflect.DeepEqual(got, []string{"row 3", "b"}) {
+		t.Errorf("Labels() of b were wrong: %v", got)
+	}
+	if got := test.Labels(); len(got) != 0 {
+		t.Errorf("Labels() of an unlabeled facade were wrong: %v", got)
+	}
+
+	output := test.Output()
+	expect := []string{
+		`labels_test.go:26: row 3: field Name: Values were not equal:`,
+		`labels_test.go:27: only: reset$`,
+		`labels_test.go:28: none$`,
+		`labels_test.go:29: row 3: empty label ignored$`,
+	}
+	for i, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[i]); !ok {
+			t.Errorf("Output %d didn't match '%s': '%s'", i, e, output[i])
+		}
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// edit is one step of a line edit script: an unchanged line (' '), a line
+// only in 'got' ('-') or a line only in 'want' ('+').  The indexes are
+// positions in the respective inputs.
+type edit struct {
+	op   byte
+	got  int
+	want int
+}
+
+// lineDiff returns a unified diff of two multi-line strings.  Lines only in
+// 'got' are prefixed with '-' and lines only in 'want' with '+'.  When a
+// changed line is replaced by another, the differing run of characters is
+// marked as '[-...-]' and '{+...+}' respectively.
+func lineDiff(got, want string) string {
+	a := strings.Split(got, "\n")
+	b := strings.Split(want, "\n")
+	edits := myers(a, b)
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("--- Got\n+++ Wanted\n")
+	for _, h := range hunks(edits) {
+		writeHunk(buf, a, b, edits[h[0]:h[1]])
+	}
+	return buf.String()
+}
+
+// myers computes a shortest edit script between two slices of lines using
+// the linear space variant of the algorithm from Eugene Myers' "An O(ND)
+// Difference Algorithm and Its Variations", so that diffing two large,
+// very different texts doesn't keep a frontier for every edit.  Within a
+// run of changes, lines only in 'got' come before lines only in 'want'.
+func myers(a, b []string) []edit {
+	size := len(a) + len(b) + 1
+	md := &myersDiff{
+		a:      a,
+		b:      b,
+		offset: size,
+		vf:     make([]int, 2*size+1),
+		vb:     make([]int, 2*size+1),
+	}
+	md.compare(0, len(a), 0, len(b))
+	return groupChanges(md.edits)
+}
+
+type myersDiff struct {
+	a, b   []string
+	offset int
+	vf, vb []int
+	edits  []edit
+}
+
+// compare appends the edits that turn a[a0:a1] into b[b0:b1].
+func (md *myersDiff) compare(a0, a1, b0, b1 int) {
+	for a0 < a1 && b0 < b1 && md.a[a0] == md.b[b0] {
+		md.edits = append(md.edits, edit{' ', a0, b0})
+		a0++
+		b0++
+	}
+	suffix := 0
+	for a0 < a1-suffix && b0 < b1-suffix && md.a[a1-1-suffix] == md.b[b1-1-suffix] {
+		suffix++
+	}
+	a1 -= suffix
+	b1 -= suffix
+
+	switch {
+	case a0 == a1:
+		for j := b0; j < b1; j++ {
+			md.edits = append(md.edits, edit{'+', a0, j})
+		}
+	case b0 == b1:
+		for i := a0; i < a1; i++ {
+			md.edits = append(md.edits, edit{'-', i, b0})
+		}
+	default:
+		x, y, u, v := md.middleSnake(a0, a1, b0, b1)
+		md.compare(a0, x, b0, y)
+		for ; x < u; x, y = x+1, y+1 {
+			md.edits = append(md.edits, edit{' ', x, y})
+		}
+		md.compare(u, a1, v, b1)
+	}
+
+	for i := 0; i < suffix; i++ {
+		md.edits = append(md.edits, edit{' ', a1 + i, b1 + i})
+	}
+}
+
+// middleSnake searches for a shortest edit path through a[a0:a1] and
+// b[b0:b1] from both ends at once and returns the start and end of the
+// diagonal run where the two searches meet.
+func (md *myersDiff) middleSnake(a0, a1, b0, b1 int) (x0, y0, x1, y1 int) {
+	n, m := a1-a0, b1-b0
+	delta := n - m
+	odd := delta%2 != 0
+	vf, vb, off := md.vf, md.vb, md.offset
+	vf[off+1], vb[off+1] = 0, 0
+
+	for d := 0; d <= (n+m+1)/2; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && vf[off+k-1] < vf[off+k+1]) {
+				x = vf[off+k+1]
+			} else {
+				x = vf[off+k-1] + 1
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < m && md.a[a0+x] == md.b[b0+y] {
+				x++
+				y++
+			}
+			vf[off+k] = x
+			if kb := delta - k; odd && kb >= -(d-1) && kb <= d-1 && x+vb[off+kb] >= n {
+				return a0 + sx, b0 + sy, a0 + x, b0 + y
+			}
+		}
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && vb[off+k-1] < vb[off+k+1]) {
+				x = vb[off+k+1]
+			} else {
+				x = vb[off+k-1] + 1
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < m && md.a[a1-1-x] == md.b[b1-1-y] {
+				x++
+				y++
+			}
+			vb[off+k] = x
+			if kf := delta - k; !odd && kf >= -d && kf <= d && x+vf[off+kf] >= n {
+				return a1 - x, b1 - y, a1 - sx, b1 - sy
+			}
+		}
+	}
+	panic("testy: no middle snake found")
+}
+
+// groupChanges reorders each run of changes so that removals come before
+// additions, which lets writeHunk pair up replaced lines.
+func groupChanges(edits []edit) []edit {
+	out := make([]edit, 0, len(edits))
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			out = append(out, edits[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(edits) && edits[j].op != ' ' {
+			j++
+		}
+		// After the removals, 'got' is past the last removed line.
+		got, want := edits[i].got, edits[i].want
+		for _, e := range edits[i:j] {
+			if e.op == '-' {
+				out = append(out, edit{'-', e.got, want})
+				got = e.got + 1
+			}
+		}
+		for _, e := range edits[i:j] {
+			if e.op == '+' {
+				out = append(out, edit{'+', got, e.want})
+			}
+		}
+		i = j
+	}
+	return out
+}
+
+// hunks groups an edit script into [start, end) ranges of edits that
+// contain changes plus surrounding context.
+func hunks(edits []edit) [][2]int {
+	var out [][2]int
+	for i := 0; i < len(edits); i++ {
+		if edits[i].op == ' ' {
+			continue
+		}
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		// Extend the hunk until there is a long enough unchanged run.
+		end, same := i, 0
+		for ; end < len(edits) && same <= 2*diffContext; end++ {
+			if edits[end].op == ' ' {
+				same++
+			} else {
+				same = 0
+			}
+		}
+		if same > diffContext {
+			end -= same - diffContext
+		}
+		if n := len(out); n > 0 && start <= out[n-1][1] {
+			out[n-1][1] = end
+		} else {
+			out = append(out, [2]int{start, end})
+		}
+		i = end - 1
+	}
+	return out
+}
+
+func writeHunk(buf *bytes.Buffer, a, b []string, edits []edit) {
+	var gotLen, wantLen int
+	for _, e := range edits {
+		if e.op != '+' {
+			gotLen++
+		}
+		if e.op != '-' {
+			wantLen++
+		}
+	}
+	fmt.Fprintf(buf, "@@ -%s +%s @@\n",
+		hunkRange(edits[0].got, gotLen), hunkRange(edits[0].want, wantLen))
+
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			buf.WriteString(" " + a[edits[i].got] + "\n")
+			i++
+			continue
+		}
+		// Collect a block of removals followed by additions so that
+		// replaced lines can be paired up for highlighting.
+		var dels, adds []string
+		for ; i < len(edits) && edits[i].op == '-'; i++ {
+			dels = append(dels, a[edits[i].got])
+		}
+		for ; i < len(edits) && edits[i].op == '+'; i++ {
+			adds = append(adds, b[edits[i].want])
+		}
+		for j := range dels {
+			if j < len(adds) {
+				dels[j], adds[j] = highlight(dels[j], adds[j])
+			}
+		}
+		for _, s := range dels {
+			buf.WriteString("-" + s + "\n")
+		}
+		for _, s := range adds {
+			buf.WriteString("+" + s + "\n")
+		}
+	}
+}
+
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// highlight marks the run of characters that differs between two lines,
+// after stripping their common prefix and suffix.
+func highlight(got, want string) (string, string) {
+	g, w := []rune(got), []rune(want)
+	pre := 0
+	for pre < len(g) && pre < len(w) && g[pre] == w[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(g)-pre && suf < len(w)-pre && g[len(g)-1-suf] == w[len(w)-1-suf] {
+		suf++
+	}
+	mark := func(r []rune, open, close string) string {
+		mid := string(r[pre : len(r)-suf])
+		return string(r[:pre]) + open + escapeRun(mid) + close + string(r[len(r)-suf:])
+	}
+	return mark(g, "[-", "-]"), mark(w, "{+", "+}")
+}
+
+// escapeRun quotes a changed run if it has characters that would otherwise
+// be invisible, such as a carriage return.
+func escapeRun(s string) string {
+	for _, r := range s {
+		if r != '\t' && !unicode.IsPrint(r) {
+			q := strconv.Quote(s)
+			return q[1 : len(q)-1]
+		}
+	}
+	return s
+}
@@ -0,0 +1,144 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+func TestLineDiff(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %d = value", i))
+	}
+	want := strings.Join(lines, "\n")
+
+	lines[9] = "line 10 = valve"
+	test.Equal(strings.Join(lines, "\n"), want)
+
+	inserted := append([]string{}, lines[:15]...)
+	inserted = append(inserted, "extra")
+	inserted = append(inserted, lines[15:]...)
+	test.Equal(strings.Join(inserted, "\n"), strings.Join(lines, "\n"))
+
+	test.Equal("one\r\ntwo", "one\ntwo")
+
+	output := test.Output()
+	if len(output) != 3 {
+		t.Fatalf("Expected 3 failures, got %d: %v", len(output), output)
+	}
+
+	expect := []string{
+		`(?m)^\s+--- Got$`,
+		`(?m)^\s+\+\+\+ Wanted$`,
+		`(?m)^\s+@@ -7,7 \+7,7 @@$`,
+		`(?m)^\s+ line 9 = value$`,
+		`(?m)^\s+-line 10 = val\[-v-\]e$`,
+		`(?m)^\s+\+line 10 = val\{\+u\+\}e$`,
+		`(?m)^\s+ line 11 = value$`,
+	}
+	for _, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[0]); !ok {
+			t.Errorf("Line diff didn't match '%s': '%s'", e, output[0])
+		}
+	}
+	if strings.Contains(output[0], "line 1 ") || strings.Contains(output[0], "line 20") {
+		t.Errorf("Line diff showed too much context: '%s'", output[0])
+	}
+
+	expect = []string{
+		`(?m)^\s+@@ -13,7 \+13,6 @@$`,
+		`(?m)^\s+-extra$`,
+	}
+	for _, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[1]); !ok {
+			t.Errorf("Line diff didn't match '%s': '%s'", e, output[1])
+		}
+	}
+	if strings.Contains(output[1], "{+") {
+		t.Errorf("Insertion shouldn't be highlighted: '%s'", output[1])
+	}
+
+	if ok, _ := regexp.MatchString(`(?m)^\s+-one\[-\\r-\]$`, output[2]); !ok {
+		t.Errorf("Invisible change wasn't escaped: '%s'", output[2])
+	}
+}
+
+func TestLineEditsAreShortest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(3)))
+		}
+		return lines
+	}
+	for i := 0; i < 2000; i++ {
+		a, b := randomLines(), randomLines()
+		ops, ok := testy.LineEdits(a, b)
+		if !ok {
+			t.Fatalf("Edit script %q doesn't turn %q into %q", ops, a, b)
+		}
+		if got, want := strings.Count(ops, " "), lcsLength(a, b); got != want {
+			t.Fatalf("Edit script %q for %q and %q keeps %d lines, not %d", ops, a, b, got, want)
+		}
+		if strings.Contains(ops, "+-") {
+			t.Fatalf("Edit script %q for %q and %q adds lines before removing others", ops, a, b)
+		}
+	}
+}
+
+func TestLineEditsMemory(t *testing.T) {
+	a := make([]string, 5000)
+	b := make([]string, 5000)
+	for i := range a {
+		a[i] = fmt.Sprintf("got %d", i)
+		b[i] = fmt.Sprintf("want %d", i)
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	ops, ok := testy.LineEdits(a, b)
+	runtime.ReadMemStats(&after)
+
+	if !ok || len(ops) != 10000 {
+		t.Fatalf("Wrong edit script of length %d", len(ops))
+	}
+	if used := after.TotalAlloc - before.TotalAlloc; used > 16<<20 {
+		t.Errorf("Diffing two 5000 line texts allocated %d bytes", used)
+	}
+}
+
+func lcsLength(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				dp[i][j] = dp[i+1][j+1] + 1
+			case dp[i+1][j] > dp[i][j+1]:
+				dp[i][j] = dp[i+1][j]
+			default:
+				dp[i][j] = dp[i][j+1]
+			}
+		}
+	}
+	return dp[0][0]
+}
@@ -0,0 +1,206 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Matcher checks a value against an expectation and describes both for
+// failure messages.  Implement it to build domain-specific checks for use
+// with the That method.
+type Matcher interface {
+	// Match reports whether v meets the expectation.
+	Match(v interface{}) bool
+	// Describe says what a matching value is, e.g. "equal to 3".
+	Describe() string
+	// DescribeMismatch says why v doesn't match, e.g. "was 4".
+	DescribeMismatch(v interface{}) string
+}
+
+// That checks if its argument satisfies a Matcher; if not, it logs an
+// error with the value and the Matcher's descriptions on subsequent lines.
+func (t *T) That(got interface{}, m Matcher) {
+	if !m.Match(got) {
+		t.report(Event{
+			Kind: EventFailure,
+			Message: fmt.Sprintf("Value did not match:\n%sWanted: %s\n   But: %s",
+				diag("   Got", got), m.Describe(), m.DescribeMismatch(got)),
+			Got:  got,
+			Want: m.Describe(),
+		})
+		t.fail()
+	}
+}
+
+// describeValue formats a value for a Matcher description.
+func describeValue(v interface{}) string {
+	return formatValue(reflect.ValueOf(v))
+}
+
+// EqualTo returns a Matcher for values equal to 'want' according to
+// reflect.DeepEqual.
+func EqualTo(want interface{}) Matcher {
+	return equalTo{want}
+}
+
+type equalTo struct {
+	want interface{}
+}
+
+func (m equalTo) Match(v interface{}) bool {
+	return reflect.DeepEqual(v, m.want)
+}
+
+func (m equalTo) Describe() string {
+	return "equal to " + describeValue(m.want)
+}
+
+func (m equalTo) DescribeMismatch(v interface{}) string {
+	if v != nil && m.want != nil && reflect.TypeOf(v) != reflect.TypeOf(m.want) {
+		return fmt.Sprintf("was %s of type %v", describeValue(v), reflect.TypeOf(v))
+	}
+	return "was " + describeValue(v)
+}
+
+// AllOf returns a Matcher for values that satisfy every one of the given
+// Matchers.  A mismatch is described by the first Matcher that fails.
+func AllOf(ms ...Matcher) Matcher {
+	return allOf(ms)
+}
+
+type allOf []Matcher
+
+func (ms allOf) Match(v interface{}) bool {
+	for _, m := range ms {
+		if !m.Match(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (ms allOf) Describe() string {
+	return joinDescriptions(ms, " and ")
+}
+
+func (ms allOf) DescribeMismatch(v interface{}) string {
+	for _, m := range ms {
+		if !m.Match(v) {
+			return m.DescribeMismatch(v)
+		}
+	}
+	return "matched"
+}
+
+// AnyOf returns a Matcher for values that satisfy at least one of the
+// given Matchers.
+func AnyOf(ms ...Matcher) Matcher {
+	return anyOf(ms)
+}
+
+type anyOf []Matcher
+
+func (ms anyOf) Match(v interface{}) bool {
+	for _, m := range ms {
+		if m.Match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ms anyOf) Describe() string {
+	return joinDescriptions(ms, " or ")
+}
+
+func (ms anyOf) DescribeMismatch(v interface{}) string {
+	reasons := make([]string, len(ms))
+	for i, m := range ms {
+		reasons[i] = m.DescribeMismatch(v)
+	}
+	return strings.Join(reasons, "; and ")
+}
+
+func joinDescriptions(ms []Matcher, sep string) string {
+	if len(ms) == 1 {
+		return ms[0].Describe()
+	}
+	descs := make([]string, len(ms))
+	for i, m := range ms {
+		descs[i] = "(" + m.Describe() + ")"
+	}
+	return strings.Join(descs, sep)
+}
+
+// Not returns a Matcher that inverts another Matcher.
+func Not(m Matcher) Matcher {
+	return not{m}
+}
+
+type not struct {
+	m Matcher
+}
+
+func (n not) Match(v interface{}) bool {
+	return !n.m.Match(v)
+}
+
+func (n not) Describe() string {
+	return "not " + n.m.Describe()
+}
+
+func (n not) DescribeMismatch(v interface{}) string {
+	return "was " + describeValue(v)
+}
+
+// Each returns a Matcher for slices and arrays whose elements all satisfy
+// the given Matcher.  A mismatch is described by the first element that
+// fails.
+func Each(m Matcher) Matcher {
+	return each{m}
+}
+
+type each struct {
+	m Matcher
+}
+
+func (e each) Match(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	if !isList(rv) {
+		return false
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if !e.m.Match(rv.Index(i).Interface()) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e each) Describe() string {
+	return "every element " + e.m.Describe()
+}
+
+func (e each) DescribeMismatch(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if !isList(rv) {
+		return fmt.Sprintf("was %s, which is not a slice or array", describeValue(v))
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if elem := rv.Index(i).Interface(); !e.m.Match(elem) {
+			return fmt.Sprintf("element [%d] %s", i, e.m.DescribeMismatch(elem))
+		}
+	}
+	return "matched"
+}
+
+func isList(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+}
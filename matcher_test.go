@@ -0,0 +1,70 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+// even is an example of a domain-specific Matcher
+type even struct{}
+
+func (even) Match(v interface{}) bool {
+	n, ok := v.(int)
+	return ok && n%2 == 0
+}
+
+func (even) Describe() string {
+	return "an even number"
+}
+
+func (even) DescribeMismatch(v interface{}) string {
+	return fmt.Sprintf("%v is odd", v)
+}
+
+func TestMatchers(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	// not failures
+	test.That(2, even{})
+	test.That(4, testy.AllOf(even{}, testy.Not(testy.EqualTo(2))))
+	test.That(3, testy.AnyOf(even{}, testy.EqualTo(3)))
+	test.That([]int{2, 4, 6}, testy.Each(even{}))
+	test.That("foo", testy.EqualTo("foo"))
+
+	// failures
+	test.Label("Row", 1).That(3, even{})
+	test.That(2, testy.AllOf(even{}, testy.Not(testy.EqualTo(2))))
+	test.That(5, testy.AnyOf(even{}, testy.EqualTo(3)))
+	test.That([]int{2, 3, 6}, testy.Each(even{}))
+	test.That(42, testy.Each(even{}))
+	test.That(int64(3), testy.EqualTo(3))
+
+	if fc := test.FailCount(); fc != 6 {
+		t.Fatalf("Incorrect FailCount. Got %d, but expected 6", fc)
+	}
+
+	output := test.Output()
+	expect := []string{
+		`(?s)matcher_test.go:\d+: Row 1: Value did not match:\n\s+Got: 3 \(int\)\n\s+Wanted: an even number\n\s+But: 3 is odd$`,
+		`(?s)Wanted: \(an even number\) and \(not equal to 2\)\n\s+But: was 2$`,
+		`(?s)Wanted: \(an even number\) or \(equal to 3\)\n\s+But: 5 is odd; and was 5$`,
+		`(?s)Wanted: every element an even number\n\s+But: element \[1\] 3 is odd$`,
+		`(?s)But: was 42, which is not a slice or array$`,
+		`(?s)Wanted: equal to 3\n\s+But: was 3 of type int64$`,
+	}
+	for i, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[i]); !ok {
+			t.Errorf("That() output %d didn't match '%s': '%s'", i, e, output[i])
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Panics checks if calling fn panics; if not, it logs an error.
+func (t *T) Panics(fn func()) {
+	if p := catchPanic(fn); !p.panicked {
+		t.report(Event{Kind: EventFailure, Message: "Function did not panic"})
+		t.fail()
+	}
+}
+
+// PanicsWith checks if calling fn panics with a value that satisfies a
+// Matcher; if not, it logs an error with the panic value, the Matcher's
+// descriptions and where the panic started on subsequent lines.
+func (t *T) PanicsWith(fn func(), m Matcher) {
+	p := catchPanic(fn)
+	switch {
+	case !p.panicked:
+		t.report(Event{Kind: EventFailure, Message: "Function did not panic", Want: m.Describe()})
+		t.fail()
+	case !m.Match(p.value):
+		t.report(Event{
+			Kind: EventFailure,
+			Message: fmt.Sprintf("Panic value did not match:\n%sWanted: %s\n   But: %s\n    At: %s",
+				diag("   Got", p.value), m.Describe(), m.DescribeMismatch(p.value), p.frame),
+			Got:  p.value,
+			Want: m.Describe(),
+		})
+		t.fail()
+	}
+}
+
+// NotPanics checks if calling fn returns without panicking; if not, it
+// logs an error with the panic value and where the panic started on
+// subsequent lines.
+func (t *T) NotPanics(fn func()) {
+	if p := catchPanic(fn); p.panicked {
+		t.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Unexpected panic:\n%s    At: %s", diag("   Got", p.value), p.frame),
+			Got:     p.value,
+		})
+		t.fail()
+	}
+}
+
+// panicInfo describes the outcome of calling a function that may panic.
+type panicInfo struct {
+	panicked bool
+	value    interface{}
+	frame    string // where the panic started, e.g. "file.go:12 (pkg.fn)"
+}
+
+// catchPanic calls fn and recovers any panic it raises.  A function that
+// exits its goroutine with runtime.Goexit, like t.FailNow, is not a panic
+// and the Goexit carries on unwinding.
+func catchPanic(fn func()) (p panicInfo) {
+	returned := false
+	defer func() {
+		if returned {
+			return
+		}
+		if p.value = recover(); p.value != nil {
+			p.panicked = true
+			p.frame = panicFrame()
+		}
+	}()
+	fn()
+	returned = true
+	return p
+}
+
+// panicFrame finds the frame that called panic, or that caused a runtime
+// panic, from within a deferred function that is recovering it.
+func panicFrame() string {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(1, pcs)])
+	unwinding := false
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.gopanic" {
+			unwinding = true
+		} else if unwinding && !strings.HasPrefix(frame.Function, "runtime.") {
+			return fmt.Sprintf("%s:%d (%s)", filepath.Base(frame.File), frame.Line, frame.Function)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
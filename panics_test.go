@@ -0,0 +1,77 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+func explode() {
+	panic("boom")
+}
+
+func mustNotPanic(is *testy.T, fn func()) {
+	is.Uplevel(1).NotPanics(fn)
+}
+
+func TestPanics(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	var empty []int
+
+	// not failures
+	test.Panics(explode)
+	test.PanicsWith(explode, testy.EqualTo("boom"))
+	test.NotPanics(func() {})
+
+	// failures
+	test.Panics(func() {})
+	test.PanicsWith(func() {}, testy.EqualTo("boom"))
+	test.PanicsWith(explode, testy.EqualTo("bang"))
+	test.Label("Index").NotPanics(func() { _ = empty[3] })
+	mustNotPanic(test, explode)
+
+	if fc := test.FailCount(); fc != 5 {
+		t.Fatalf("Incorrect FailCount. Got %d, but expected 5", fc)
+	}
+
+	output := test.Output()
+	expect := []string{
+		`panics_test.go:37: Function did not panic$`,
+		`panics_test.go:38: Function did not panic$`,
+		`(?s)panics_test.go:39: Panic value did not match:\n\s+Got: "boom"\n\s+Wanted: equal to "bang"\n\s+But: was "boom"\n\s+At: panics_test.go:18 \(github.com/xdg/testy_test.explode\)$`,
+		`(?s)panics_test.go:40: Index: Unexpected panic:\n\s+Got: runtime error: index out of range \[3\] with length 0 \(runtime.boundsError\)\n\s+At: panics_test.go:40 \(github.com/xdg/testy_test.TestPanics.func\d+\)$`,
+		`(?s)panics_test.go:41: Unexpected panic:\n\s+Got: "boom"\n\s+At: panics_test.go:18 `,
+	}
+	for i, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[i]); !ok {
+			t.Errorf("Output %d didn't match '%s': '%s'", i, e, output[i])
+		}
+	}
+}
+
+func TestPanicsGoexit(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		test.NotPanics(runtime.Goexit)
+		t.Errorf("NotPanics() stopped runtime.Goexit")
+	}()
+	<-done
+
+	if fc := test.FailCount(); fc != 0 {
+		t.Errorf("Goexit was reported as a panic: %v", test.Output())
+	}
+}
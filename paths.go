@@ -0,0 +1,128 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// PathStyle selects how file names are shown in the locations of log
+// messages.
+type PathStyle int
+
+const (
+	// PathDefault uses the style named by the TESTY_PATHS environment
+	// variable, "base", "relative" or "absolute", or PathBase if it isn't
+	// set.
+	PathDefault PathStyle = iota
+	// PathBase shows only the last element of the path, like the testing
+	// package, e.g. "handler_test.go".
+	PathBase
+	// PathRelative shows the path relative to the root of the module
+	// containing the file, e.g. "api/v2/handler_test.go".  Outside of a
+	// module, it is relative to the GOPATH src directory, and otherwise
+	// absolute.
+	PathRelative
+	// PathAbsolute shows the full path.
+	PathAbsolute
+)
+
+// pathsEnv is the environment variable that sets the default path style.
+const pathsEnv = "TESTY_PATHS"
+
+// WithPaths returns a testy.T struct that shows file names in log message
+// locations in the given style.  Use PathRelative or PathAbsolute when
+// many files share a name, so an editor's quickfix list can find the right
+// one.
+func (t T) WithPaths(style PathStyle) *T {
+	t.paths = style
+	return &t
+}
+
+// formatPath renders a file name from a runtime frame in a path style.
+func formatPath(file string, style PathStyle) string {
+	if style == PathDefault {
+		style = envPathStyle()
+	}
+	switch style {
+	case PathAbsolute:
+		return file
+	case PathRelative:
+		return relativePath(file)
+	}
+	// Truncate file name at last file name separator.
+	if index := strings.LastIndex(file, "/"); index >= 0 {
+		return file[index+1:]
+	} else if index = strings.LastIndex(file, "\\"); index >= 0 {
+		return file[index+1:]
+	}
+	return file
+}
+
+func envPathStyle() PathStyle {
+	switch os.Getenv(pathsEnv) {
+	case "relative":
+		return PathRelative
+	case "absolute":
+		return PathAbsolute
+	}
+	return PathBase
+}
+
+// relativePath returns a file name relative to its module root or GOPATH
+// src directory, or unchanged if it is in neither.
+func relativePath(file string) string {
+	root := moduleRoot(filepath.Dir(file))
+	if root == "" {
+		for _, p := range filepath.SplitList(build.Default.GOPATH) {
+			if src := filepath.Join(p, "src"); strings.HasPrefix(file, src+string(filepath.Separator)) {
+				root = src
+				break
+			}
+		}
+	}
+	if root == "" {
+		return file
+	}
+	rel, err := filepath.Rel(root, file)
+	if err != nil {
+		return file
+	}
+	return filepath.ToSlash(rel)
+}
+
+var moduleRoots = struct {
+	sync.Mutex
+	dirs map[string]string
+}{dirs: make(map[string]string)}
+
+// moduleRoot returns the closest directory at or above dir that has a
+// go.mod file, or an empty string if there is none.  Results are cached,
+// since every log message of a test usually comes from the same directory.
+func moduleRoot(dir string) string {
+	moduleRoots.Lock()
+	defer moduleRoots.Unlock()
+	if root, ok := moduleRoots.dirs[dir]; ok {
+		return root
+	}
+	root := ""
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			root = d
+			break
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	moduleRoots.dirs[dir] = root
+	return root
+}
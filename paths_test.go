@@ -0,0 +1,67 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+func TestWithPaths(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+	_, file, _, _ := runtime.Caller(0)
+
+	test.Error("default")
+	test.WithPaths(testy.PathAbsolute).Error("absolute")
+	test.WithPaths(testy.PathAbsolute).WithStack().Error("stack")
+	t.Setenv("TESTY_PATHS", "absolute")
+	test.Error("from environment")
+	test.WithPaths(testy.PathBase).Error("base")
+
+	output := test.Output()
+	expect := []string{
+		`^paths_test.go:\d+: default$`,
+		`^` + regexp.QuoteMeta(file) + `:\d+: absolute$`,
+		`(?s)\n\s+` + regexp.QuoteMeta(file) + `:\d+ github.com/xdg/testy_test.TestWithPaths$`,
+		`^` + regexp.QuoteMeta(file) + `:\d+: from environment$`,
+		`^paths_test.go:\d+: base$`,
+	}
+	for i, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[i]); !ok {
+			t.Errorf("Output %d didn't match '%s': '%s'", i, e, output[i])
+		}
+	}
+}
+
+func TestRelativePaths(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "api", "v2"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.ToSlash(filepath.Join(root, "api", "v2", "handler_test.go"))
+	if got := testy.FormatPath(file, testy.PathRelative); got != "api/v2/handler_test.go" {
+		t.Errorf("Relative path was wrong: %s", got)
+	}
+	if got := testy.FormatPath(file, testy.PathBase); got != "handler_test.go" {
+		t.Errorf("Base path was wrong: %s", got)
+	}
+
+	outside := filepath.ToSlash(filepath.Join(filepath.Dir(root), "handler_test.go"))
+	if got := testy.FormatPath(outside, testy.PathRelative); got != outside {
+		t.Errorf("Path outside a module was changed: %s", got)
+	}
+}
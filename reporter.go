@@ -0,0 +1,146 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// EventKind identifies what an Event describes.
+type EventKind int
+
+const (
+	// EventCaseStart is sent when NewCase (or New) creates a test case.
+	EventCaseStart EventKind = iota
+	// EventLog is sent by Log and Logf.
+	EventLog
+	// EventFailure is sent by Fail, Error, Fatal and the test helpers.
+	EventFailure
+	// EventSkip is sent by Skip and Skipf.
+	EventSkip
+)
+
+var eventKindNames = map[EventKind]string{
+	EventCaseStart: "start",
+	EventLog:       "log",
+	EventFailure:   "fail",
+	EventSkip:      "skip",
+}
+
+func (k EventKind) String() string {
+	if s, ok := eventKindNames[k]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// Event is a structured record of something reported through a testy.T
+// facade.  Location fields are not set for EventCaseStart.
+type Event struct {
+	Kind    EventKind
+	Case    string     // Test case name given to NewCase
+	TB      testing.TB // The test the facade wraps
+	Label   string     // Labels joined by ": ", without a trailing colon
+	File    string     // Full path to the source file of the reported line
+	Line    int
+	Message string // Empty for Fail and FailNow
+	Attrs   []Attr // Attributes added with With, in the order added
+
+	// Stack holds the calls that led to a failure, innermost first, for
+	// facades from WithStack or when the -testy.stack flag is set.
+	// Frames in the runtime, testing and testy packages are left out.
+	Stack []Frame
+
+	// Got and Want hold the values compared by helpers like Equal.  They
+	// are nil for events that don't involve values.
+	Got  interface{}
+	Want interface{}
+
+	autoName bool // Case came from the calling function, in New
+}
+
+// Frame is one call in the stack of an Event.
+type Frame struct {
+	Function string // Fully qualified function name
+	File     string // Full path to the source file
+	Line     int
+}
+
+// Attr is a key/value attribute attached to events with the With method.
+type Attr struct {
+	Key   string
+	Value interface{}
+}
+
+// String formats an attribute as "key=value".  String values are quoted if
+// they are empty or contain spaces, quotes or equal signs.
+func (a Attr) String() string {
+	if s, ok := a.Value.(string); ok && (s == "" || strings.ContainsAny(s, " \t\n\"=")) {
+		return a.Key + "=" + strconv.Quote(s)
+	}
+	return fmt.Sprintf("%s=%v", a.Key, a.Value)
+}
+
+// Reporter receives events from testy.T facades.  Each facade delivers
+// events to its own accumulator, which produces the text returned by Done
+// and Output, and then to every Reporter registered with AddReporter.
+// Reporters may be called from parallel tests, so they must be safe for
+// concurrent use.
+type Reporter interface {
+	Report(e Event)
+}
+
+// ReporterFunc adapts an ordinary function to the Reporter interface.
+type ReporterFunc func(e Event)
+
+// Report calls f(e).
+func (f ReporterFunc) Report(e Event) {
+	f(e)
+}
+
+type reporterEntry struct {
+	r Reporter
+}
+
+var reporters struct {
+	sync.RWMutex
+	list []*reporterEntry
+}
+
+// AddReporter registers a Reporter to receive events from all facades.  A
+// typical place to call it is TestMain.  It returns a function that removes
+// the Reporter again.
+func AddReporter(r Reporter) (remove func()) {
+	entry := &reporterEntry{r}
+	reporters.Lock()
+	defer reporters.Unlock()
+	reporters.list = append(reporters.list, entry)
+	return func() {
+		reporters.Lock()
+		defer reporters.Unlock()
+		for i, e := range reporters.list {
+			if e == entry {
+				reporters.list = append(reporters.list[:i:i], reporters.list[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func registeredReporters() []Reporter {
+	reporters.RLock()
+	defer reporters.RUnlock()
+	out := make([]Reporter, len(reporters.list))
+	for i, e := range reporters.list {
+		out[i] = e.r
+	}
+	return out
+}
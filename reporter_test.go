@@ -0,0 +1,88 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+type recordingReporter struct {
+	sync.Mutex
+	events []testy.Event
+}
+
+func (r *recordingReporter) Report(e testy.Event) {
+	r.Lock()
+	defer r.Unlock()
+	r.events = append(r.events, e)
+}
+
+func TestReporter(t *testing.T) {
+	rec := &recordingReporter{}
+	remove := testy.AddReporter(rec)
+
+	mock := &testing.T{}
+	test := testy.NewCase(mock, "Reporter test")
+	test.Log("hello")                   // Line 34
+	test.Label("Row", 1).Equal(1, 2)    // Line 35
+	test.Fail()                         // Line 36
+	test.Label("Row", 2).True(1+1 == 3) // Line 37
+
+	remove()
+	test.Error("not reported")
+
+	if len(rec.events) != 5 {
+		t.Fatalf("Expected 5 events, got %d: %v", len(rec.events), rec.events)
+	}
+
+	expect := []struct {
+		kind    testy.EventKind
+		label   string
+		line    int
+		message string
+	}{
+		{testy.EventCaseStart, "", 0, ""},
+		{testy.EventLog, "", 34, "hello"},
+		{testy.EventFailure, "Row 1", 35, "Values were not equal:\n   Got: 1 (int)\nWanted: 2 (int)"},
+		{testy.EventFailure, "", 36, ""},
+		{testy.EventFailure, "Row 2", 37, "Expression was not true: 1+1 == 3\n    1+1: 2"},
+	}
+	for i, e := range expect {
+		got := rec.events[i]
+		if got.Case != "Reporter test" {
+			t.Errorf("Event %d had wrong case: '%s'", i, got.Case)
+		}
+		if got.Kind != e.kind || got.Label != e.label || got.Line != e.line || got.Message != e.message {
+			t.Errorf("Event %d was wrong: got %+v, expected %+v", i, got, e)
+		}
+		if e.line != 0 && filepath.Base(got.File) != "reporter_test.go" {
+			t.Errorf("Event %d had wrong file: '%s'", i, got.File)
+		}
+	}
+
+	if got, want := rec.events[2].Got, 1; got != want {
+		t.Errorf("Event had wrong 'got' value: %v", got)
+	}
+	if got, want := rec.events[2].Want, 2; got != want {
+		t.Errorf("Event had wrong 'want' value: %v", got)
+	}
+	if got := rec.events[2].Kind.String(); got != "fail" {
+		t.Errorf("EventKind had wrong string: '%s'", got)
+	}
+
+	// The default text reporter still sees everything
+	if fc := test.FailCount(); fc != 4 {
+		t.Errorf("Incorrect FailCount. Got %d, but expected 4", fc)
+	}
+	if n := len(test.Output()); n != 4 {
+		t.Errorf("Incorrect Output length. Got %d, but expected 4", n)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+type config struct {
+	Name string
+}
+
+func TestRequire(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	var cfg *config
+	reached := false
+	done := make(chan struct{})
+	// FailNow exits the goroutine, so the checks run on their own
+	go func() {
+		defer close(done)
+		test.Require().Label("Config").NotNil(cfg)
+		reached = true
+		test.Equal(cfg.Name, "example")
+	}()
+	<-done
+
+	if reached {
+		t.Errorf("Require() didn't stop the test")
+	}
+	if !mock.Failed() {
+		t.Errorf("Require() failure not recorded in test object")
+	}
+	if fc := test.FailCount(); fc != 1 {
+		t.Errorf("Incorrect FailCount. Got %d, but expected 1", fc)
+	}
+	output := test.Output()
+	if ok, _ := regexp.MatchString(`require_test.go:\d+: Config: Expression was nil: cfg`, output[0]); !ok {
+		t.Errorf("NotNil() had wrong error message: '%s'", output[0])
+	}
+
+	// Other facades are unaffected
+	test.True(false)
+	test.Error("still running")
+	if fc := test.FailCount(); fc != 3 {
+		t.Errorf("Incorrect FailCount. Got %d, but expected 3", fc)
+	}
+}
+
+// TestRequireChild is run in a child process by TestRequireOutput so that
+// the output delivered after FailNow can be inspected.
+func TestRequireChild(t *testing.T) {
+	if os.Getenv("TESTY_TEST_CHILD") != "TestRequireChild" {
+		t.Skip("only runs as a child of TestRequireOutput")
+	}
+	is := testy.New(t)
+	is.Log("before the failure")
+	is.Require().Equal(1, 2)
+	is.Log("never logged")
+}
+
+func TestRequireOutput(t *testing.T) {
+	log := childOutput("TestRequireChild")
+
+	expect := []string{
+		`require_test.go:\d+: TestRequireChild: 1 test failed`,
+		`require_test.go:\d+: before the failure`,
+		`require_test.go:\d+: Values were not equal`,
+	}
+	for _, e := range expect {
+		if ok, _ := regexp.MatchString(e, log); !ok {
+			t.Errorf("Output didn't match '%s': '%s'", e, log)
+		}
+	}
+	if ok, _ := regexp.MatchString(`never logged`, log); ok {
+		t.Errorf("Require() didn't stop the test: '%s'", log)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+func TestRun(t *testing.T) {
+	rec := &recordingReporter{}
+	remove := testy.AddReporter(rec)
+	defer remove()
+
+	is := testy.New(t).Label("Table")
+	var ran []string
+	for _, name := range []string{"one", "two"} {
+		ok := is.Run(name, func(is *testy.T) {
+			ran = append(ran, name)
+			is.Log("hello from", name)
+			is.True(true)
+		})
+		if !ok {
+			t.Errorf("Run(%q) reported failure", name)
+		}
+	}
+
+	if len(ran) != 2 {
+		t.Fatalf("Subtests didn't run: %v", ran)
+	}
+
+	var cases, logs []string
+	for _, e := range rec.events {
+		switch e.Kind {
+		case testy.EventCaseStart:
+			cases = append(cases, e.Case)
+		case testy.EventLog:
+			logs = append(logs, e.Case+" "+e.Label+" "+e.Message)
+			if ok, _ := regexp.MatchString(`run_test.go$`, e.File); !ok {
+				t.Errorf("Log had wrong location: %s:%d", e.File, e.Line)
+			}
+		}
+	}
+	expect := []string{"TestRun", "TestRun/one", "TestRun/two"}
+	if len(cases) != 3 || cases[0] != expect[0] || cases[1] != expect[1] || cases[2] != expect[2] {
+		t.Errorf("Wrong case names: %v", cases)
+	}
+	if len(logs) != 2 || logs[0] != "TestRun/one Table hello from one" {
+		t.Errorf("Wrong child logs: %v", logs)
+	}
+}
+
+// TestRunChild is run in a child process by TestRunFailures so that
+// failing subtests don't fail the real test.
+func TestRunChild(t *testing.T) {
+	if os.Getenv("TESTY_TEST_CHILD") != "TestRunChild" {
+		t.Skip("only runs as a child of TestRunFailures")
+	}
+	is := testy.New(t)
+	is.Run("fails", func(is *testy.T) {
+		is.Error("first")
+		is.Error("second")
+	})
+	is.Run("passes", func(is *testy.T) {})
+	is.Logf("FailCount is %d", is.FailCount())
+}
+
+func TestRunFailures(t *testing.T) {
+	log := childOutput("TestRunChild")
+
+	expect := []string{
+		`run_test.go:\d+: TestRunChild/fails: 2 tests failed`,
+		`run_test.go:\d+: first`,
+		`run_test.go:\d+: TestRunChild/passes: all tests passed`,
+		`run_test.go:\d+: TestRunChild: 2 tests failed`,
+		`run_test.go:\d+: FailCount is 2`,
+	}
+	for _, e := range expect {
+		if ok, _ := regexp.MatchString(e, log); !ok {
+			t.Errorf("Output didn't match '%s': '%s'", e, log)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const updateHint = "run 'go test -args -testy.update' to update it"
+
+// MatchInline checks if the pretty-printed form of 'got' equals the string
+// 'want'; if not, it logs an error with a diff on subsequent lines.
+// Strings are compared as they are; other values are printed like Go
+// composite literals, one field or element per line.
+//
+// When the tests run with 'go test -args -testy.update', MatchInline
+// rewrites its own call in the test source instead, replacing the 'want'
+// argument with a string literal of the current value.  The argument must
+// already be a string literal.
+func (t *T) MatchInline(got interface{}, want string) {
+	text := prettyPrint(got)
+	if text == want {
+		return
+	}
+	if *update {
+		t.updateInline(Event{Kind: EventLog, Message: "Updated inline snapshot"}, "MatchInline", text)
+		return
+	}
+	t.report(Event{
+		Kind:    EventFailure,
+		Message: fmt.Sprintf("Snapshot did not match; %s:\n%s", updateHint, diffDiag(text, want)),
+		Got:     got,
+		Want:    want,
+	})
+	t.fail()
+}
+
+// Snapshot is like MatchInline, but the snapshot string may be left out.
+// A new check can be written as 'is.Snapshot(value)', which fails until
+// the tests run with -testy.update and the current value is added to the
+// call as a second argument.
+func (t *T) Snapshot(got interface{}, want ...string) {
+	text := prettyPrint(got)
+	if len(want) > 1 {
+		t.report(Event{Kind: EventFailure, Message: "Snapshot takes at most one snapshot string", Got: got})
+		t.fail()
+		return
+	}
+	if len(want) == 1 && text == want[0] {
+		return
+	}
+	if *update {
+		t.updateInline(Event{Kind: EventLog, Message: "Updated inline snapshot"}, "Snapshot", text)
+		return
+	}
+	if len(want) == 0 {
+		t.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Snapshot is missing; run 'go test -args -testy.update' to record it:\n%s", text),
+			Got:     got,
+		})
+		t.fail()
+		return
+	}
+	t.report(Event{
+		Kind:    EventFailure,
+		Message: fmt.Sprintf("Snapshot did not match; %s:\n%s", updateHint, diffDiag(text, want[0])),
+		Got:     got,
+		Want:    want[0],
+	})
+	t.fail()
+}
+
+// updateInline is like report, but first rewrites the call of 'method' at
+// the reported location so its snapshot argument is 'text'.  If that
+// fails, the event becomes a failure explaining why.
+func (t *T) updateInline(e Event, method string, text string) {
+	t.decorate(&e)
+	if err := rewriteInline(e.File, e.Line, method, text); err != nil {
+		e.Kind = EventFailure
+		e.Message = fmt.Sprintf("Can't update snapshot: %v", err)
+		t.deliver(e)
+		t.fail()
+		return
+	}
+	t.deliver(e)
+}
+
+// sourceEdit replaces the bytes from start to end of a source file.
+type sourceEdit struct {
+	start, end int
+	text       string
+}
+
+// pendingEdits holds the snapshot updates made to each source file during
+// this run.  Calls are found with the cached parse of the original source,
+// so every update rewrites the original with all the edits so far.
+var pendingEdits = struct {
+	sync.Mutex
+	files map[string][]sourceEdit
+}{files: make(map[string][]sourceEdit)}
+
+// rewriteInline finds the call of 'method' on the given line of a source
+// file and sets its snapshot argument to a string literal of 'text'.
+func rewriteInline(path string, line int, method string, text string) error {
+	f := parseSource(path)
+	if f == nil {
+		return fmt.Errorf("can't parse %s", path)
+	}
+	call := f.findCall(line, method)
+	if call == nil {
+		return fmt.Errorf("can't find a single call of %s at %s:%d", method, path, line)
+	}
+
+	lit := snapshotLiteral(text)
+	var edit sourceEdit
+	switch len(call.Args) {
+	case 1:
+		end := f.fset.Position(call.Args[0].End()).Offset
+		edit = sourceEdit{start: end, end: end, text: ", " + lit}
+	case 2:
+		if arg, ok := call.Args[1].(*ast.BasicLit); !ok || arg.Kind != token.STRING {
+			return errors.New("the snapshot argument is not a string literal")
+		}
+		edit = sourceEdit{
+			start: f.fset.Position(call.Args[1].Pos()).Offset,
+			end:   f.fset.Position(call.Args[1].End()).Offset,
+			text:  lit,
+		}
+	default:
+		return fmt.Errorf("the call of %s at %s:%d has %d arguments", method, path, line, len(call.Args))
+	}
+
+	pendingEdits.Lock()
+	defer pendingEdits.Unlock()
+	edits := addEdit(pendingEdits.files[path], edit)
+
+	buf := new(bytes.Buffer)
+	last := 0
+	for _, e := range edits {
+		buf.Write(f.src[last:e.start])
+		buf.WriteString(e.text)
+		last = e.end
+	}
+	buf.Write(f.src[last:])
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, src, info.Mode()); err != nil {
+		return err
+	}
+	pendingEdits.files[path] = edits
+	return nil
+}
+
+// addEdit adds an edit to a list sorted by position, replacing any earlier
+// edit of the same place.
+func addEdit(edits []sourceEdit, edit sourceEdit) []sourceEdit {
+	out := make([]sourceEdit, 0, len(edits)+1)
+	for _, e := range edits {
+		if e.start != edit.start {
+			out = append(out, e)
+		}
+	}
+	out = append(out, edit)
+	sort.Slice(out, func(i, j int) bool { return out[i].start < out[j].start })
+	return out
+}
+
+// snapshotLiteral returns a Go string literal for text, using a raw string
+// for multi-line text when possible so that it reads naturally in source.
+func snapshotLiteral(text string) string {
+	if strings.Contains(text, "\n") && !strings.ContainsAny(text, "`\r") && utf8.ValidString(text) {
+		return "`" + text + "`"
+	}
+	return strconv.Quote(text)
+}
+
+// prettyPrint formats a value for a snapshot.  Strings are returned as
+// they are.  Other values are printed like Go composite literals, with one
+// field, element or map entry per line and map keys sorted, so the output
+// is stable and diffs well.
+func prettyPrint(x interface{}) string {
+	if s, ok := x.(string); ok {
+		return s
+	}
+	p := &prettyPrinter{visited: make(map[prettyVisit]bool)}
+	p.print(reflect.ValueOf(x), 0)
+	return p.buf.String()
+}
+
+type prettyPrinter struct {
+	buf     bytes.Buffer
+	visited map[prettyVisit]bool
+}
+
+// prettyVisit identifies a pointer, map or slice being printed, so that a
+// value that contains itself, directly or through an interface, is printed
+// as "<cycle>" instead of recursing forever.  Slices of different lengths
+// can share a pointer without being the same value.
+type prettyVisit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+func newPrettyVisit(v reflect.Value) prettyVisit {
+	key := prettyVisit{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+	return key
+}
+
+// enter marks a reference as being printed and reports whether it wasn't
+// already; if it was, it prints "<cycle>".
+func (p *prettyPrinter) enter(v reflect.Value) bool {
+	key := newPrettyVisit(v)
+	if p.visited[key] {
+		p.buf.WriteString("<cycle>")
+		return false
+	}
+	p.visited[key] = true
+	return true
+}
+
+func (p *prettyPrinter) leave(v reflect.Value) {
+	delete(p.visited, newPrettyVisit(v))
+}
+
+func (p *prettyPrinter) indent(depth int) {
+	p.buf.WriteString(strings.Repeat("  ", depth))
+}
+
+func (p *prettyPrinter) print(v reflect.Value, depth int) {
+	if !v.IsValid() {
+		p.buf.WriteString("nil")
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			p.buf.WriteString("nil")
+			return
+		}
+		if !p.enter(v) {
+			return
+		}
+		defer p.leave(v)
+		p.buf.WriteByte('&')
+		p.print(v.Elem(), depth)
+	case reflect.Interface:
+		p.print(v.Elem(), depth)
+	case reflect.Struct:
+		if v.NumField() == 0 {
+			fmt.Fprintf(&p.buf, "%v{}", v.Type())
+			return
+		}
+		fmt.Fprintf(&p.buf, "%v{\n", v.Type())
+		for i := 0; i < v.NumField(); i++ {
+			p.indent(depth + 1)
+			p.buf.WriteString(v.Type().Field(i).Name + ": ")
+			p.print(v.Field(i), depth+1)
+			p.buf.WriteString(",\n")
+		}
+		p.indent(depth)
+		p.buf.WriteByte('}')
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			p.buf.WriteString("nil")
+			return
+		}
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			fmt.Fprintf(&p.buf, "%v(%q)", v.Type(), v.Bytes())
+			return
+		}
+		if v.Len() == 0 {
+			fmt.Fprintf(&p.buf, "%v{}", v.Type())
+			return
+		}
+		if v.Kind() == reflect.Slice {
+			if !p.enter(v) {
+				return
+			}
+			defer p.leave(v)
+		}
+		fmt.Fprintf(&p.buf, "%v{\n", v.Type())
+		for i := 0; i < v.Len(); i++ {
+			p.indent(depth + 1)
+			p.print(v.Index(i), depth+1)
+			p.buf.WriteString(",\n")
+		}
+		p.indent(depth)
+		p.buf.WriteByte('}')
+	case reflect.Map:
+		if v.IsNil() {
+			p.buf.WriteString("nil")
+			return
+		}
+		if v.Len() == 0 {
+			fmt.Fprintf(&p.buf, "%v{}", v.Type())
+			return
+		}
+		if !p.enter(v) {
+			return
+		}
+		defer p.leave(v)
+		keys := v.MapKeys()
+		names := make([]string, len(keys))
+		for i, k := range keys {
+			kp := &prettyPrinter{visited: p.visited}
+			kp.print(k, depth+1)
+			names[i] = kp.buf.String()
+		}
+		sort.Sort(keySorter{keys, names})
+		fmt.Fprintf(&p.buf, "%v{\n", v.Type())
+		for i, k := range keys {
+			p.indent(depth + 1)
+			p.buf.WriteString(names[i] + ": ")
+			p.print(v.MapIndex(k), depth+1)
+			p.buf.WriteString(",\n")
+		}
+		p.indent(depth)
+		p.buf.WriteByte('}')
+	case reflect.String:
+		p.buf.WriteString(strconv.Quote(v.String()))
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if v.IsNil() {
+			p.buf.WriteString("nil")
+			return
+		}
+		fmt.Fprintf(&p.buf, "%v(...)", v.Type())
+	default:
+		fmt.Fprintf(&p.buf, "%v", v)
+	}
+}
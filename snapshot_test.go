@@ -0,0 +1,157 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+type account struct {
+	Name  string
+	Roles []string
+	Limit map[string]int
+	Owner *account
+	notes []byte
+}
+
+func TestMatchInline(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	acct := account{
+		Name:  "alice",
+		Roles: []string{"admin"},
+		Limit: map[string]int{"b": 2, "a": 1},
+		Owner: &account{Name: "bob"},
+		notes: []byte("hi"),
+	}
+
+	// not failures
+	test.MatchInline("plain text", "plain text")
+	test.MatchInline(42, "42")
+	test.MatchInline(acct, `testy_test.account{
+  Name: "alice",
+  Roles: []string{
+    "admin",
+  },
+  Limit: map[string]int{
+    "a": 1,
+    "b": 2,
+  },
+  Owner: &testy_test.account{
+    Name: "bob",
+    Roles: nil,
+    Limit: nil,
+    Owner: nil,
+    notes: nil,
+  },
+  notes: []uint8("hi"),
+}`)
+	test.Snapshot(struct{}{}, "struct {}{}")
+
+	// failures
+	test.MatchInline([]int{1, 2}, "[]int{\n  1,\n  3,\n}")
+	test.Snapshot(3.5)
+	test.Snapshot(1, "2")
+	test.Snapshot(1, "1", "2")
+
+	if fc := test.FailCount(); fc != 4 {
+		t.Fatalf("Incorrect FailCount. Got %d, but expected 4", fc)
+	}
+
+	output := test.Output()
+	expect := []string{
+		`(?s)snapshot_test.go:\d+: Snapshot did not match; run 'go test -args -testy.update' to update it:\n\s+--- Got\n\s+\+\+\+ Wanted\n.*\s+-  \[-2-\],\n\s+\+  \{\+3\+\},\n`,
+		`(?s)snapshot_test.go:\d+: Snapshot is missing; run 'go test -args -testy.update' to record it:\n\s+3.5$`,
+		`(?s)snapshot_test.go:\d+: Snapshot did not match; run 'go test -args -testy.update' to update it:\n\s+Got: "1"\n\s+Wanted: "2"$`,
+		`snapshot_test.go:\d+: Snapshot takes at most one snapshot string$`,
+	}
+	for i, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[i]); !ok {
+			t.Errorf("Output %d didn't match '%s': '%s'", i, e, output[i])
+		}
+	}
+}
+
+const inlineSource = `package demo
+
+func TestDemo(t *testing.T) {
+	is := testy.New(t)
+	is.MatchInline(42, "41")
+	is.Snapshot([]int{1})
+	is.MatchInline("x", other)
+}
+`
+
+const inlineUpdated = "package demo\n\nfunc TestDemo(t *testing.T) {\n\tis := testy.New(t)\n\tis.MatchInline(42, \"42\")\n\tis.Snapshot([]int{1}, `[]int{\n  1,\n}`)\n\tis.MatchInline(\"x\", other)\n}\n"
+
+func TestRewriteInline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "demo_test.go")
+	if err := os.WriteFile(path, []byte(inlineSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Edits are made to the original source, so line numbers stay valid.
+	if err := testy.RewriteInline(path, 6, "Snapshot", "[]int{\n  1,\n}"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := testy.RewriteInline(path, 5, "MatchInline", "42"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := testy.RewriteInline(path, 7, "MatchInline", "x"); err == nil {
+		t.Errorf("Expected an error for a snapshot that isn't a literal")
+	}
+	if err := testy.RewriteInline(path, 3, "MatchInline", "x"); err == nil {
+		t.Errorf("Expected an error for a line without a call")
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != inlineUpdated {
+		t.Errorf("Rewritten source was wrong:\n%s", got)
+	}
+}
+
+func TestMatchInlineCycles(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	m := map[string]interface{}{"n": 1}
+	m["self"] = m
+	s := []interface{}{"a", nil}
+	s[1] = s
+
+	test.MatchInline(m, `map[string]interface {}{
+  "n": 1,
+  "self": <cycle>,
+}`)
+	test.MatchInline(s, `[]interface {}{
+  "a",
+  <cycle>,
+}`)
+	// Sharing without a cycle is printed in full.
+	shared := []int{1}
+	test.MatchInline([][]int{shared, shared}, `[][]int{
+  []int{
+    1,
+  },
+  []int{
+    1,
+  },
+}`)
+
+	if fc := test.FailCount(); fc != 0 {
+		t.Errorf("Unexpected failures: %v", test.Output())
+	}
+}
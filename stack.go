@@ -0,0 +1,46 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy
+
+import (
+	"flag"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// traceFlag is set by running tests with 'go test -args -testy.stack'.
+var traceFlag = flag.Bool("testy.stack", false, "show a stack trace beneath every failure")
+
+// WithStack returns a testy.T struct that adds the stack of calls that led
+// to each failure beneath its message, so failures deep in shared fixture
+// code can be traced back.  Frames in the runtime, testing and testy
+// packages are left out.  Running tests with 'go test -args -testy.stack'
+// does the same for every facade.
+func (t T) WithStack() *T {
+	t.stack = true
+	return &t
+}
+
+// testyPrefix is the prefix of the names of functions in this package.
+var testyPrefix = reflect.TypeOf(T{}).PkgPath() + "."
+
+// trimStack converts runtime frames to event frames, leaving out those in
+// the runtime, testing and testy packages.
+func trimStack(frames []runtime.Frame) []Frame {
+	var stack []Frame
+	for _, f := range frames {
+		if f.Function == "" ||
+			strings.HasPrefix(f.Function, "runtime.") ||
+			strings.HasPrefix(f.Function, "testing.") ||
+			strings.HasPrefix(f.Function, testyPrefix) {
+			continue
+		}
+		stack = append(stack, Frame{Function: f.Function, File: f.File, Line: f.Line})
+	}
+	return stack
+}
@@ -0,0 +1,86 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"flag"
+	"regexp"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+func loadFixture(is *testy.T) {
+	is.Uplevel(1).Error("fixture failed") // Line 18
+}
+
+func setupFixture(is *testy.T) {
+	loadFixture(is) // Line 22
+}
+
+func TestWithStack(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	setupFixture(test.WithStack()) // Line 29
+	test.WithStack().Log("not a failure")
+	setupFixture(test)
+
+	flag.Set("testy.stack", "true")
+	test.Error("flagged") // Line 34
+	flag.Set("testy.stack", "false")
+
+	output := test.Output()
+	expect := []string{
+		`(?s)stack_test.go:22: fixture failed\n\s+Stack:\n\s+stack_test.go:18 github.com/xdg/testy_test.loadFixture\n\s+stack_test.go:22 github.com/xdg/testy_test.setupFixture\n\s+stack_test.go:29 github.com/xdg/testy_test.TestWithStack$`,
+		`stack_test.go:30: not a failure$`,
+		`stack_test.go:22: fixture failed$`,
+		`(?s)stack_test.go:34: flagged\n\s+Stack:\n\s+stack_test.go:34 github.com/xdg/testy_test.TestWithStack$`,
+	}
+	for i, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[i]); !ok {
+			t.Errorf("Output %d didn't match '%s': '%s'", i, e, output[i])
+		}
+	}
+
+	events := test.Events()
+	if s := events[1].Stack; len(s) != 3 || s[0].Function != "github.com/xdg/testy_test.loadFixture" || s[0].Line != 18 {
+		t.Errorf("Event stack was wrong: %v", s)
+	}
+}
+
+func deepHelper(is *testy.T, n int) {
+	is.Helper()
+	if n > 0 {
+		deepHelper(is, n-1)
+		return
+	}
+	is.Error("deep failure")
+}
+
+func TestDeepStack(t *testing.T) {
+	mock := &testing.T{}
+	test := testy.New(mock)
+
+	deepHelper(test.WithStack(), 100) // Line 69
+	test.Uplevel(1000).Error("too far")
+
+	output := test.Output()
+	expect := []string{
+		`(?s)^stack_test.go:69: deep failure\n`,
+		`^\?\?\?:1: too far$`,
+	}
+	for i, e := range expect {
+		if ok, _ := regexp.MatchString(e, output[i]); !ok {
+			t.Errorf("Output %d didn't match '%s': '%s'", i, e, output[i])
+		}
+	}
+
+	if s := test.Events()[1].Stack; len(s) != 102 || s[101].Line != 69 {
+		t.Errorf("Event stack had %d frames, expected 102", len(s))
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testy_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/xdg/testy"
+)
+
+func TestBenchmarkFacade(t *testing.T) {
+	mock := &testing.B{}
+	test := testy.NewCase(mock, "Benchmark")
+
+	test.Equal(1, 2)
+
+	if !mock.Failed() {
+		t.Errorf("Equal() not recorded in benchmark object")
+	}
+	output := test.Output()
+	if ok, _ := regexp.MatchString(`tb_test.go:\d+: Values were not equal`, output[0]); !ok {
+		t.Errorf("Equal() had wrong error message: '%s'", output[0])
+	}
+
+	rec := &recordingReporter{}
+	remove := testy.AddReporter(rec)
+	defer remove()
+
+	var ran int
+	testing.Benchmark(func(b *testing.B) {
+		is := testy.NewCase(b, "Bench")
+		is.Run("sub", func(is *testy.T) {
+			ran++
+			is.True(true)
+		})
+	})
+
+	if ran == 0 {
+		t.Fatalf("Benchmark subtest didn't run")
+	}
+	for _, e := range rec.events {
+		if e.Kind == testy.EventCaseStart && e.Case == "Bench/sub" {
+			return
+		}
+	}
+	t.Errorf("Benchmark subtest case wasn't started: %v", rec.events)
+}
+
+func TestFuzzFacade(t *testing.T) {
+	rec := &recordingReporter{}
+	remove := testy.AddReporter(rec)
+	defer remove()
+
+	mock := &testing.F{}
+	test := testy.NewCase(mock, "Fuzz")
+
+	ok := test.Run("seed", func(is *testy.T) {
+		is.Error("bad seed")
+	})
+
+	if ok {
+		t.Errorf("Run() didn't report failure")
+	}
+	if fc := test.FailCount(); fc != 1 {
+		t.Errorf("Incorrect FailCount. Got %d, but expected 1", fc)
+	}
+	if !mock.Failed() {
+		t.Errorf("Error() not recorded in fuzz object")
+	}
+
+	var found bool
+	for _, e := range rec.events {
+		if e.Kind == testy.EventFailure && e.Case == "Fuzz/seed" && e.Message == "bad seed" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Failure not reported from child case: %v", rec.events)
+	}
+}
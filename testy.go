@@ -22,8 +22,8 @@
 // easy to implement your own.
 //
 // The following example shows how to set up testy and use test helpers.
-// In particular, note the use of 'defer' and a closure to schedule testy
-// to output the log at the end of the function's execution.
+// Testy delivers its log to the testing.T when the test finishes; call
+// ManualDone (or Done) if you would rather deliver it yourself.
 //
 //	package example
 //
@@ -34,7 +34,6 @@
 //
 // 	func TestExample(t *testing.T) {
 // 		is := testy.New(t)
-// 		defer func() { t.Logf(is.Done()) }()
 //
 // 		is.True(1+1 == 3)
 // 		is.False(2 == 2)
@@ -54,13 +53,13 @@
 // would look in Vim's quickfix window:
 //
 //	...
-// 	_examples/example_test.go|15| Values were not equal:
+// 	_examples/example_test.go|14| Values were not equal:
 // 	|| 			   Got: 1 (int)
 // 	|| 			Wanted: 2 (int)
-// 	_examples/example_test.go|16| Values were not equal:
+// 	_examples/example_test.go|15| Values were not equal:
 // 	|| 			   Got: 1 (float64)
 // 	|| 			Wanted: 1 (int)
-// 	_examples/example_test.go|17| Values were not equal:
+// 	_examples/example_test.go|16| Values were not equal:
 // 	|| 			   Got: "foo\tbar"
 // 	|| 			Wanted: "foo\tbaz"
 //	...
@@ -70,16 +69,15 @@
 //
 // 	func TestExample(t *testing.T) {
 // 		is := testy.New(t)
-// 		defer func() { t.Logf(is.Done()) }()
 //
 // 		// Check for numbers equal to 3
 // 		for i := 1; i <= 5; i++ {
-// 			is.Label("Checking", i).True(i == 3) // Line 13
+// 			is.Label("Checking", i).True(i == 3) // Line 12
 // 		}
 //
 // 		// Check for positive, even numbers
 // 		for i := -1; i <= 2; i++ {
-// 			checkEvenPositive(is, i)             // Line 18
+// 			checkEvenPositive(is, i)             // Line 17
 // 		}
 // 	}
 //
@@ -97,20 +95,21 @@
 //
 // The example above would return errors to a quickfix window like this:
 // 	...
-// 	_examples/example_test.go|13| Checking 1: Expression was not true
-// 	_examples/example_test.go|13| Checking 2: Expression was not true
-// 	_examples/example_test.go|13| Checking 4: Expression was not true
-// 	_examples/example_test.go|13| Checking 5: Expression was not true
-// 	_examples/example_test.go|18| Testing -1: Value was not positive
-// 	_examples/example_test.go|18| Testing -1: Value was not even
-// 	_examples/example_test.go|18| Testing 0: Value was not positive
-// 	_examples/example_test.go|18| Testing 1: Value was not even
+// 	_examples/example_test.go|12| Checking 1: Expression was not true: i == 3
+// 	_examples/example_test.go|12| Checking 2: Expression was not true: i == 3
+// 	_examples/example_test.go|12| Checking 4: Expression was not true: i == 3
+// 	_examples/example_test.go|12| Checking 5: Expression was not true: i == 3
+// 	_examples/example_test.go|17| Testing -1: Value was not positive
+// 	_examples/example_test.go|17| Testing -1: Value was not even
+// 	_examples/example_test.go|17| Testing 0: Value was not positive
+// 	_examples/example_test.go|17| Testing 1: Value was not even
 // 	...
 package testy
 
 import (
 	"bytes"
 	"fmt"
+	"go/constant"
 	"reflect"
 	"regexp"
 	"runtime"
@@ -118,26 +117,37 @@ import (
 	"strings"
 	"sync"
 	"testing"
+
+	"github.com/xdg/testy/internal/recording"
 )
 
-// T is a facade around the testing.T type passed to Test functions.  It
-// intercepts log messages to attribute them to the correct level of the
+// T is a facade around the testing.T type passed to Test functions, or
+// any other testing.TB such as the testing.B passed to Benchmark functions.
+// It intercepts log messages to attribute them to the correct level of the
 // call stack.
 type T struct {
-	test      *testing.T
+	test      testing.TB
 	context   *accumulator
 	caseName  string
-	label     string
+	autoName  bool // caseName came from the calling function, in New
+	labels    []string
+	attrs     []Attr
 	callDepth int
+	fatal     bool
+	stack     bool
+	paths     PathStyle
+	nanEqual  bool
 }
 
 var nameStripper = regexp.MustCompile(`^.*\.`)
 
-// New wraps a testy.T struct around a testing.T struct. The resulting
-// struct can be used in the same way the testing.T struct would be, plus
-// has additional methods specific to Testy.  It calls NewCase with
-// the calling function's name as the test case name.
-func New(t *testing.T) *T {
+// New wraps a testy.T struct around a testing.T struct (or testing.B,
+// testing.F, etc.). The resulting struct can be used in the same way the
+// testing.T struct would be, plus has additional methods specific to
+// Testy.  It calls NewCase with the calling function's name as the test
+// case name.
+func New(t testing.TB) *T {
+	t.Helper()
 	var n string
 	pc, _, _, ok := runtime.Caller(1)
 	if ok {
@@ -146,23 +156,77 @@ func New(t *testing.T) *T {
 	} else {
 		n = "Anonymous function"
 	}
-	return NewCase(t, n)
+	return newCase(t, n, true)
 }
 
-// NewCase wraps a testy.T struct around a testing.T struct. The resulting
-// struct can be used in the same way the testing.T struct would be, plus
-// has additional methods specific to Testy.  It takes a name argument
-// that is used in the summary line during log output.
-func NewCase(t *testing.T, name string) *T {
-	return &T{test: t, caseName: name, callDepth: 1, context: &accumulator{}}
+// NewCase wraps a testy.T struct around a testing.T struct (or testing.B,
+// testing.F, etc.). The resulting struct can be used in the same way the
+// testing.T struct would be, plus has additional methods specific to
+// Testy.  It takes a name argument that is used in the summary line during
+// log output.
+//
+// When the test finishes, the output of Done is delivered to the
+// testing.T Log method automatically, unless Done or ManualDone was called
+// first.
+func NewCase(t testing.TB, name string) *T {
+	t.Helper()
+	return newCase(t, name, false)
+}
+
+// newCase makes a facade for New and NewCase.  autoName is set for New,
+// whose case name comes from the calling function.  Facades around a
+// recording.TB from NewScratch get a scratch accumulator, so testytest can
+// check a helper's expected failures without them reaching JUnit or JSON
+// reports.
+func newCase(t testing.TB, name string, autoName bool) *T {
+	t.Helper()
+	context := &accumulator{scratch: recording.IsScratch(t)}
+	is := &T{test: t, caseName: name, autoName: autoName, callDepth: 1, context: context}
+	is.deliver(Event{Kind: EventCaseStart, Case: name, TB: t})
+	t.Cleanup(is.autoDone)
+	return is
 }
 
 // Label returns a testy.T struct that will prefix a label to all log
 // messages.  The label is constructed by concatenating arguments separated
 // by a space (like fmt.Sprintln without the trailing space).  A colon
-// character and space will be added automatically
+// character and space will be added automatically.  Labels stack: if t
+// already has labels, the new one is added after them, so a helper that
+// labels the facade it was given keeps the caller's label too, e.g.
+// "Row 3: Field Name: Values were not equal".
 func (t T) Label(s ...interface{}) *T {
-	t.label = strings.TrimSpace(fmt.Sprintln(s...)) + ": "
+	if label := strings.TrimSpace(fmt.Sprintln(s...)); label != "" {
+		// Limit the capacity so facades never share appended labels.
+		t.labels = append(t.labels[:len(t.labels):len(t.labels)], label)
+	}
+	return &t
+}
+
+// ResetLabel is like Label, but replaces any labels t already has instead
+// of adding to them.  With no arguments, it removes all labels.
+func (t T) ResetLabel(s ...interface{}) *T {
+	t.labels = nil
+	return t.Label(s...)
+}
+
+// Labels returns a copy of the labels that will prefix log messages, from
+// the first added to the last.
+func (t T) Labels() []string {
+	return append([]string(nil), t.labels...)
+}
+
+// With returns a testy.T struct that attaches a key/value attribute to
+// all log messages.  Attributes appear after any labels in text output as
+// "key=value", and in the Attrs field of events for reporters and Events.
+// Setting a key again replaces its value.
+func (t T) With(key string, value interface{}) *T {
+	attrs := make([]Attr, 0, len(t.attrs)+1)
+	for _, a := range t.attrs {
+		if a.Key != key {
+			attrs = append(attrs, a)
+		}
+	}
+	t.attrs = append(attrs, Attr{Key: key, Value: value})
 	return &t
 }
 
@@ -173,10 +237,72 @@ func (t T) Uplevel(depth int) *T {
 	return &t
 }
 
+// Run runs f as a subtest of t called name, like the testing.T Run method,
+// and reports whether f succeeded.  The subtest gets a child facade with
+// its own log and summary line, using the case name "<parent>/<name>".  The
+// child inherits the labels and call depth of t, and its failures are also
+// counted by the FailCount of t.  If t is in manual mode, the child is too
+// and f must deliver the child's Done output itself.
+//
+// Subtests are supported for testing.T and testing.B.  For any other
+// testing.TB, f is run directly with a child facade around the same
+// underlying test, so FailNow or SkipNow in f will stop the parent.
+func (t *T) Run(name string, f func(t *T)) bool {
+	t.test.Helper()
+	switch test := t.test.(type) {
+	case *testing.T:
+		return test.Run(name, func(test *testing.T) {
+			test.Helper()
+			f(t.child(test, name))
+		})
+	case *testing.B:
+		return test.Run(name, func(test *testing.B) {
+			test.Helper()
+			f(t.child(test, name))
+		})
+	}
+	parent := *t
+	parent.autoName = false // the child's test name is the parent's
+	child := parent.child(t.test, name)
+	f(child)
+	return child.FailCount() == 0
+}
+
+func (t T) child(test testing.TB, name string) *T {
+	test.Helper()
+	t.test = test
+	t.caseName = t.caseName + "/" + name
+	t.context = t.context.child()
+	t.deliver(Event{Kind: EventCaseStart, Case: t.caseName, TB: test})
+	test.Cleanup(t.autoDone)
+	return &t
+}
+
+// Require returns a testy.T struct whose test helpers, Error and Errorf
+// stop the test with FailNow after recording a failure.  Use it for checks
+// that later code depends on, such as a pointer being non-nil before its
+// fields are used.  Output recorded so far is not lost: the testing package
+// still runs the cleanup that delivers it (or deferred calls to Done, in
+// manual mode) when FailNow ends the test.
+func (t T) Require() *T {
+	t.fatal = true
+	return &t
+}
+
 // Done returns any test log output formatted suitably for passing to a
-// testing.T struct Logf method.
+// testing.T struct Logf method.  Calling Done means the caller will
+// deliver the output, so it turns off automatic delivery like ManualDone.
 func (t *T) Done() string {
-	return t.summary() + strings.Join(t.context.outputCopy(), "\n")
+	t.context.setManual()
+	return t.output()
+}
+
+// ManualDone turns off automatic delivery of the Done output when the test
+// finishes, for callers who deliver it themselves.  It affects every facade
+// of the test case and returns the facade it was called on.
+func (t *T) ManualDone() *T {
+	t.context.setManual()
+	return t
 }
 
 // FailCount returns the number of Fail, Error, Fatal or test helper
@@ -191,23 +317,32 @@ func (t T) Output() []string {
 	return t.context.outputCopy()
 }
 
+// Events returns a copy of the slice of events recorded by the testy.T
+// struct, including those without a message, such as from Fail, and the
+// EventCaseStart that begins the test case.
+func (t T) Events() []Event {
+	return t.context.eventsCopy()
+}
+
 // Helper functions
 
-// True checks if its argument is true; if false, it logs an error.
+// True checks if its argument is true; if false, it logs an error.  When
+// the source is available, the error shows the expression that was passed,
+// e.g. "Expression was not true: len(items) == want", followed by the
+// values of any of its sub-expressions that are known, such as constants.
 func (t *T) True(cond bool) {
 	if !cond {
-		t.context.incFailCount()
-		t.context.log(t.decorate("Expression was not true"))
-		t.test.Fail()
+		t.reportExpr(Event{Kind: EventFailure, Message: "Expression was not true"}, "True", constant.MakeBool(false))
+		t.fail()
 	}
 }
 
-// False checks if its argument is false; if true, it logs an error.
+// False checks if its argument is false; if true, it logs an error.  Like
+// True, the error shows the expression that was passed.
 func (t *T) False(cond bool) {
 	if cond {
-		t.context.incFailCount()
-		t.context.log(t.decorate("Expression was not false"))
-		t.test.Fail()
+		t.reportExpr(Event{Kind: EventFailure, Message: "Expression was not false"}, "False", constant.MakeBool(true))
+		t.fail()
 	}
 }
 
@@ -226,58 +361,72 @@ func checkNil(x interface{}) bool {
 }
 
 // Nil checks if its argument is nil (literal or nil slice, map, etc.); if
-// non-nil, it logs an error.
+// non-nil, it logs an error showing the expression and its value.
 func (t *T) Nil(got interface{}) {
 	if !checkNil(got) {
-		t.context.incFailCount()
-		t.context.log(t.decorate("Expression was not nil"))
-		t.test.Fail()
+		t.reportExpr(Event{Kind: EventFailure, Message: "Expression was not nil", Got: got}, "Nil", nil)
+		t.fail()
 	}
 }
 
-// Nil checks if its argument is nil (literal or nil slice, map, etc.); if
-// non-nil, it logs an error.
+// NotNil checks if its argument is not nil (literal or nil slice, map,
+// etc.); if nil, it logs an error showing the expression.
 func (t *T) NotNil(got interface{}) {
 	if checkNil(got) {
-		t.context.incFailCount()
-		t.context.log(t.decorate("Expression was nil"))
-		t.test.Fail()
+		t.reportExpr(Event{Kind: EventFailure, Message: "Expression was nil", Got: got}, "NotNil", nil)
+		t.fail()
 	}
 }
 
 // Equal checks if its arguments are equal using reflect.DeepEqual.  It
 // is subject to all the usual limitations of that function.  If the values
 // are not equal, an error is logged and the 'got' and 'want' values are
-// logged on subsequent lines for comparison.
+// logged on subsequent lines for comparison.  For structs, maps, slices and
+// arrays of the same type, only the paths that differ are logged, e.g.
+// '.Users[3].Zip: got "02139", want "02138"'.  Strings containing newlines
+// are compared with a unified line diff.
 func (t *T) Equal(got, want interface{}) {
 	if got == nil || want == nil {
-		t.context.incFailCount()
-		t.context.log(t.decorate(
-			fmt.Sprintf("Can't safely compare nil values for equality:\n%s%s", diag("   Got", got), diag("Wanted", want))))
-		t.test.Fail()
+		t.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Can't safely compare nil values for equality:\n%s%s", diag("   Got", got), diag("Wanted", want)),
+			Got:     got,
+			Want:    want,
+		})
+		t.fail()
 		return
 	}
 	if !reflect.DeepEqual(got, want) {
-		t.context.incFailCount()
-		t.context.log(t.decorate(
-			fmt.Sprintf("Values were not equal:\n%s%s", diag("   Got", got), diag("Wanted", want))))
-		t.test.Fail()
+		t.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Values were not equal:\n%s", diffDiag(got, want)),
+			Got:     got,
+			Want:    want,
+		})
+		t.fail()
 	}
 }
 
 // Unequal inverts the logic of Equal but is otherwise similar.
 func (t *T) Unequal(got, want interface{}) {
 	if got == nil || want == nil {
-		t.context.incFailCount()
-		t.context.log(t.decorate(
-			fmt.Sprintf("Can't safely compare nil values for equality:\n%s%s", diag("   Got", got), diag("Got", want))))
-		t.test.Fail()
+		t.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Can't safely compare nil values for equality:\n%s%s", diag("   Got", got), diag("Got", want)),
+			Got:     got,
+			Want:    want,
+		})
+		t.fail()
 		return
 	}
 	if reflect.DeepEqual(got, want) {
-		t.context.incFailCount()
-		t.context.log(t.decorate(fmt.Sprintf("Values were not unequal:\n%s", diag("  Both", got))))
-		t.test.Fail()
+		t.report(Event{
+			Kind:    EventFailure,
+			Message: fmt.Sprintf("Values were not unequal:\n%s", diag("  Both", got)),
+			Got:     got,
+			Want:    want,
+		})
+		t.fail()
 	}
 }
 
@@ -286,14 +435,14 @@ func (t *T) Unequal(got, want interface{}) {
 
 // Fail marks the test as having failed.
 func (t *T) Fail() {
-	t.context.incFailCount()
+	t.report(Event{Kind: EventFailure})
 	t.test.Fail()
 }
 
 // FailNow marks the test as having failed and stops execution.  It is
 // subject to the same restrictions as FailNow from the testing package.
 func (t *T) FailNow() {
-	t.context.incFailCount()
+	t.report(Event{Kind: EventFailure})
 	t.test.FailNow()
 }
 
@@ -305,52 +454,48 @@ func (t *T) Failed() bool {
 // Log joins its arguments by spaces like fmt.Sprintln and records the
 // result for later delivery by the Done method.
 func (t *T) Log(args ...interface{}) {
-	t.context.log(t.decorate(fmt.Sprintln(args...)))
+	t.report(Event{Kind: EventLog, Message: fmt.Sprintln(args...)})
 }
 
 // Logf joins its arguments like fmt.Sprintf and records the result for later
 // delivery by the Done method.
 func (t *T) Logf(format string, args ...interface{}) {
-	t.context.log(t.decorate(fmt.Sprintf(format, args...)))
+	t.report(Event{Kind: EventLog, Message: fmt.Sprintf(format, args...)})
 }
 
 // Error is equivalent to Log followed by Fail
 func (t *T) Error(args ...interface{}) {
-	t.context.incFailCount()
-	t.context.log(t.decorate(fmt.Sprintln(args...)))
-	t.test.Fail()
+	t.report(Event{Kind: EventFailure, Message: fmt.Sprintln(args...)})
+	t.fail()
 }
 
 // Errorf is equivalent to Logf followed by Fail
 func (t *T) Errorf(format string, args ...interface{}) {
-	t.context.incFailCount()
-	t.context.log(t.decorate(fmt.Sprintf(format, args...)))
-	t.test.Fail()
+	t.report(Event{Kind: EventFailure, Message: fmt.Sprintf(format, args...)})
+	t.fail()
 }
 
 // Fatal is equivalent to Log followed by FailNow
 func (t *T) Fatal(args ...interface{}) {
-	t.context.incFailCount()
-	t.context.log(t.decorate(fmt.Sprintln(args...)))
+	t.report(Event{Kind: EventFailure, Message: fmt.Sprintln(args...)})
 	t.test.FailNow()
 }
 
 // Fatalf is equivalent to Logf followed by FailNow
 func (t *T) Fatalf(format string, args ...interface{}) {
-	t.context.incFailCount()
-	t.context.log(t.decorate(fmt.Sprintf(format, args...)))
+	t.report(Event{Kind: EventFailure, Message: fmt.Sprintf(format, args...)})
 	t.test.FailNow()
 }
 
 // Skip is equivalent to Log followed by SkipNow
 func (t *T) Skip(args ...interface{}) {
-	t.context.log(t.decorate(fmt.Sprintln(args...)))
+	t.report(Event{Kind: EventSkip, Message: fmt.Sprintln(args...)})
 	t.test.SkipNow()
 }
 
 // Skipf is equivalent to Logf followed by SkipNow
 func (t *T) Skipf(format string, args ...interface{}) {
-	t.context.log(t.decorate(fmt.Sprintf(format, args...)))
+	t.report(Event{Kind: EventSkip, Message: fmt.Sprintf(format, args...)})
 	t.test.SkipNow()
 }
 
@@ -379,47 +524,147 @@ func (t T) summary() string {
 	return fmt.Sprintf("%s: %d tests failed\n", t.caseName, count)
 }
 
-// copied from core testing package for formatting similarity
-func (t T) decorate(s string) string {
-	// decorate + public func depth
-	_, file, line, ok := runtime.Caller(1 + t.callDepth)
-	if ok {
-		// Truncate file name at last file name separator.
-		if index := strings.LastIndex(file, "/"); index >= 0 {
-			file = file[index+1:]
-		} else if index = strings.LastIndex(file, "\\"); index >= 0 {
-			file = file[index+1:]
-		}
-	} else {
-		file = "???"
-		line = 1
+// fail marks the underlying test as failed after a helper, Error or Errorf
+// has recorded a failure.  In Require mode, it stops the test.
+func (t *T) fail() {
+	if t.fatal {
+		t.test.FailNow()
 	}
-	buf := new(bytes.Buffer)
-	// Every line is indented at least one tab.
-	buf.WriteByte('\t')
-	fmt.Fprintf(buf, "%s:%d: %s", file, line, t.label)
-	lines := strings.Split(s, "\n")
-	if l := len(lines); l > 1 && lines[l-1] == "" {
-		lines = lines[:l-1]
+	t.test.Fail()
+}
+
+func (t T) output() string {
+	return t.summary() + strings.Join(t.context.outputCopy(), "\n")
+}
+
+// autoDone is registered as a cleanup function of the underlying test to
+// deliver the log output when the test finishes.
+func (t *T) autoDone() {
+	if t.context.isManual() {
+		return
 	}
-	for i, line := range lines {
-		if i > 0 {
-			// Unlike package testing, second and subsequent lines are NOT
-			// indented an extra tab as package testing will do it for us.
-			buf.WriteString("\n\t")
+	t.test.Helper()
+	t.test.Log(strings.TrimSuffix(t.output(), "\n"))
+}
+
+// report decorates an event and delivers it.  It must be called directly
+// from the public method that is reporting the event.
+func (t *T) report(e Event) {
+	t.decorate(&e)
+	t.deliver(e)
+}
+
+// reportExpr is like report, but adds the source text of the argument to
+// the call of 'method' at the reported location to the message, followed
+// by the values of any sub-expressions that are known given the argument's
+// value 'result'.  For non-nil values, the value is shown too.
+func (t *T) reportExpr(e Event, method string, result constant.Value) {
+	t.decorate(&e)
+	expr, values := sourceExpr(e.File, e.Line, method, result)
+	if expr != "" {
+		e.Message += ": " + expr
+	}
+	for _, v := range values {
+		e.Message += "\n    " + v.expr + ": " + v.value
+	}
+	if !checkNil(e.Got) {
+		e.Message += "\n" + strings.TrimSuffix(diag("   Got", e.Got), "\n")
+	}
+	t.deliver(e)
+}
+
+// decorate fills in the case name, label and calling location of an event.
+// The location skips the frames of any functions marked as helpers.  For
+// failures, it also fills in the stack if the facade or the -testy.stack
+// flag asks for it.
+func (t T) decorate(e *Event) {
+	// Callers + decorate + report, leaving the public func at index 0.
+	// Grow the buffer until it holds the whole stack, so deep helpers
+	// aren't cut off.
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	for n == len(pcs) {
+		pcs = make([]uintptr, 2*len(pcs))
+		n = runtime.Callers(3, pcs)
+	}
+	frames := runtime.CallersFrames(pcs[:n])
+	var stack []runtime.Frame
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, frame)
+		if !more {
+			break
 		}
-		buf.WriteString(line)
 	}
-	buf.WriteByte('\n')
-	return buf.String()
+
+	i := t.callDepth
+	for i < len(stack) && t.isHelper(stack[i].Function) {
+		i++
+	}
+	file, line := "???", 1
+	if i < len(stack) && stack[i].File != "" {
+		file, line = stack[i].File, stack[i].Line
+	}
+	e.Case = t.caseName
+	e.TB = t.test
+	e.Label = strings.Join(t.labels, ": ")
+	e.Attrs = t.attrs
+	e.File = file
+	e.Line = line
+	e.Message = strings.TrimSuffix(e.Message, "\n")
+	if e.Kind == EventFailure && (t.stack || *traceFlag) {
+		e.Stack = trimStack(stack)
+	}
 }
 
-// Accumulator stores test results and guards concurrent access
+// deliver sends an event to the facade's accumulator, then to any
+// registered reporters unless the facade is a scratch one.
+func (t *T) deliver(e Event) {
+	e.autoName = t.autoName
+	t.context.record(e, t.paths)
+	if t.context.scratch {
+		return
+	}
+	for _, r := range registeredReporters() {
+		r.Report(e)
+	}
+}
+
+// Accumulator stores test results and guards concurrent access.  It is the
+// default Reporter, keeping the text log returned by Output and Done.
 
 type accumulator struct {
 	mutex     sync.RWMutex
 	failCount int
-	output    []string // any logging, not just failures
+	output    []string        // any logging, not just failures
+	events    []Event         // every event reported
+	manual    bool            // Done output is delivered by the caller
+	parent    *accumulator    // set for subtests; failures are counted there too
+	scratch   bool            // events stay here and aren't sent to reporters
+	helpers   map[string]bool // names of functions marked with Helper
+}
+
+func (a *accumulator) child() *accumulator {
+	return &accumulator{parent: a, manual: a.isManual(), scratch: a.scratch}
+}
+
+// Report records the event, counts failures and logs any event with a
+// message.
+func (a *accumulator) Report(e Event) {
+	a.record(e, PathDefault)
+}
+
+// record is like Report, but logs file names in the given style.
+func (a *accumulator) record(e Event, style PathStyle) {
+	a.mutex.Lock()
+	a.events = append(a.events, e)
+	a.mutex.Unlock()
+	if e.Kind == EventFailure {
+		a.incFailCount()
+	}
+	if e.Message != "" {
+		a.log(formatEvent(e, style))
+	}
 }
 
 func (a *accumulator) getFailCount() int {
@@ -438,6 +683,12 @@ func (a *accumulator) outputCopy() []string {
 	return out
 }
 
+func (a *accumulator) eventsCopy() []Event {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return append([]Event(nil), a.events...)
+}
+
 func (a *accumulator) log(s string) {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
@@ -445,9 +696,84 @@ func (a *accumulator) log(s string) {
 }
 
 func (a *accumulator) incFailCount() {
+	for ; a != nil; a = a.parent {
+		a.mutex.Lock()
+		a.failCount++
+		a.mutex.Unlock()
+	}
+}
+
+func (a *accumulator) addHelper(name string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.helpers == nil {
+		a.helpers = make(map[string]bool)
+	}
+	a.helpers[name] = true
+}
+
+// isHelper reports whether a function was marked as a helper in this test
+// case or any of its parents.
+func (a *accumulator) isHelper(name string) bool {
+	for ; a != nil; a = a.parent {
+		a.mutex.RLock()
+		found := a.helpers[name]
+		a.mutex.RUnlock()
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *accumulator) isManual() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.manual
+}
+
+func (a *accumulator) setManual() {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
-	a.failCount++
+	a.manual = true
+}
+
+// copied from core testing package for formatting similarity
+func formatEvent(e Event, style PathStyle) string {
+	buf := new(bytes.Buffer)
+	// Every line is indented at least one tab.
+	buf.WriteByte('\t')
+	fmt.Fprintf(buf, "%s:%d: ", formatPath(e.File, style), e.Line)
+	if e.Label != "" {
+		buf.WriteString(e.Label + ": ")
+	}
+	if len(e.Attrs) > 0 {
+		attrs := make([]string, len(e.Attrs))
+		for i, a := range e.Attrs {
+			attrs[i] = a.String()
+		}
+		buf.WriteString(strings.Join(attrs, " ") + ": ")
+	}
+	lines := strings.Split(e.Message, "\n")
+	if l := len(lines); l > 1 && lines[l-1] == "" {
+		lines = lines[:l-1]
+	}
+	if len(e.Stack) > 0 {
+		lines = append(lines, "Stack:")
+		for _, f := range e.Stack {
+			lines = append(lines, fmt.Sprintf("  %s:%d %s", formatPath(f.File, style), f.Line, f.Function))
+		}
+	}
+	for i, line := range lines {
+		if i > 0 {
+			// Unlike package testing, second and subsequent lines are NOT
+			// indented an extra tab as package testing will do it for us.
+			buf.WriteString("\n\t")
+		}
+		buf.WriteString(line)
+	}
+	buf.WriteByte('\n')
+	return buf.String()
 }
 
 // internal comparison support functions
@@ -466,3 +792,25 @@ func diag(prefix string, value interface{}) string {
 		return fmt.Sprintf("%s: %v (%v)\n", prefix, value, vType)
 	}
 }
+
+// diffDiag describes how unequal values differ.  Composite values of the
+// same type get a line per differing path and multi-line strings get a
+// line diff; anything else gets 'got' and 'want' lines from diag.
+func diffDiag(got, want interface{}) string {
+	if reflect.TypeOf(got) == reflect.TypeOf(want) {
+		if isMultiline(got) || isMultiline(want) {
+			return lineDiff(reflect.ValueOf(got).String(), reflect.ValueOf(want).String())
+		}
+		if isComposite(got) {
+			if diffs := diffValues(got, want); len(diffs) > 0 {
+				return strings.Join(diffs, "\n") + "\n"
+			}
+		}
+	}
+	return diag("   Got", got) + diag("Wanted", want)
+}
+
+func isMultiline(x interface{}) bool {
+	v := reflect.ValueOf(x)
+	return v.Kind() == reflect.String && strings.Contains(v.String(), "\n")
+}
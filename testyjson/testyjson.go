@@ -0,0 +1,128 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package testyjson decodes the events written by testy.JSONReporter.
+//
+// A reporter from testy.NewJSONReporter writes one JSON object per line;
+// read them with NewDecoder.  A reporter from testy.NewJSONLogReporter
+// logs each object through the test, so the events end up in the test
+// output; find them with ParseLine, or with ReadGoTestJSON when the tests
+// ran with 'go test -json'.
+package testyjson
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// Prefix marks log lines that hold an event.  It is the same as
+// testy.JSONLogPrefix, repeated here so that tools can read events without
+// importing testy.
+const Prefix = "testy-event: "
+
+// Event is a decoded testy event.  See testy.JSONReporter for the meaning
+// of each field.
+type Event struct {
+	Time    time.Time         `json:"time"`
+	Test    string            `json:"test,omitempty"`
+	Case    string            `json:"case,omitempty"`
+	Kind    string            `json:"kind"`
+	Label   string            `json:"label,omitempty"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+	File    string            `json:"file,omitempty"`
+	Line    int               `json:"line,omitempty"`
+	Message string            `json:"message,omitempty"`
+	Got     string            `json:"got,omitempty"`
+	Want    string            `json:"want,omitempty"`
+	Stack   []Frame           `json:"stack,omitempty"`
+
+	// Package is the package the event came from.  It is only set by
+	// ReadGoTestJSON.
+	Package string `json:"-"`
+}
+
+// Frame is one call in the stack of an Event.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// Decoder reads events written one per line by testy.NewJSONReporter.
+type Decoder struct {
+	dec *json.Decoder
+}
+
+// NewDecoder returns a Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(r)}
+}
+
+// Decode returns the next event, or io.EOF when there are no more.
+func (d *Decoder) Decode() (Event, error) {
+	var e Event
+	err := d.dec.Decode(&e)
+	return e, err
+}
+
+// ParseLine returns the event in a line of test output logged by
+// testy.NewJSONLogReporter, and false if the line doesn't hold one.  The
+// line may have the file and line prefix added by the testing package.
+func ParseLine(line string) (Event, bool) {
+	i := strings.Index(line, Prefix)
+	if i < 0 {
+		return Event{}, false
+	}
+	var e Event
+	if err := json.Unmarshal([]byte(strings.TrimSpace(line[i+len(Prefix):])), &e); err != nil {
+		return Event{}, false
+	}
+	return e, true
+}
+
+// testEvent is a record of 'go test -json' output, as described by 'go doc
+// test2json'.
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Output  string
+}
+
+// ReadGoTestJSON reads the output of 'go test -json' and returns the testy
+// events logged in it, in order.  Other records and output are ignored.
+// Output split across several records is joined before it is parsed.
+func ReadGoTestJSON(r io.Reader) ([]Event, error) {
+	var events []Event
+	partial := make(map[[2]string]string)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		var te testEvent
+		if err := json.Unmarshal(scanner.Bytes(), &te); err != nil {
+			return events, err
+		}
+		if te.Action != "output" {
+			continue
+		}
+		key := [2]string{te.Package, te.Test}
+		out := partial[key] + te.Output
+		if !strings.HasSuffix(out, "\n") {
+			partial[key] = out
+			continue
+		}
+		delete(partial, key)
+		if e, ok := ParseLine(out); ok {
+			e.Package = te.Package
+			events = append(events, e)
+		}
+	}
+	return events, scanner.Err()
+}
@@ -0,0 +1,60 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testyjson_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xdg/testy/testyjson"
+)
+
+const goTestJSON = `{"Action":"run","Package":"example.com/app","Test":"TestUsers"}
+{"Action":"output","Package":"example.com/app","Test":"TestUsers","Output":"=== RUN   TestUsers\n"}
+{"Action":"output","Package":"example.com/app","Test":"TestUsers","Output":"    json.go:70: testy-event: {\"time\":\"2026-10-16T12:00:00Z\",\"test\":\"TestUsers\",\"case\":\"TestUsers\",\"kind\":\"start\"}\n"}
+{"Action":"output","Package":"example.com/app","Test":"TestUsers","Output":"    json.go:70: testy-event: {\"time\":\"2026-10-16T12:00:01Z\",\"test\":\"TestUsers\",\"kind\":\"fail\","}
+{"Action":"output","Package":"example.com/app","Test":"TestUsers","Output":"\"file\":\"/src/app/users_test.go\",\"line\":12,\"message\":\"Values were not equal\",\"got\":\"1\",\"want\":\"2\"}\n"}
+{"Action":"output","Package":"example.com/app","Test":"TestUsers","Output":"    users_test.go:9: TestUsers: 1 test failed\n"}
+{"Action":"fail","Package":"example.com/app","Test":"TestUsers","Elapsed":0}
+`
+
+func TestReadGoTestJSON(t *testing.T) {
+	events, err := testyjson.ReadGoTestJSON(strings.NewReader(goTestJSON))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d: %+v", len(events), events)
+	}
+	if e := events[0]; e.Kind != "start" || e.Case != "TestUsers" || e.Package != "example.com/app" {
+		t.Errorf("Start event was wrong: %+v", e)
+	}
+	e := events[1]
+	if e.Kind != "fail" || e.File != "/src/app/users_test.go" || e.Line != 12 || e.Got != "1" || e.Want != "2" {
+		t.Errorf("Failure event was wrong: %+v", e)
+	}
+	if e.Time.Second() != 1 {
+		t.Errorf("Failure event time was wrong: %v", e.Time)
+	}
+
+	if _, err := testyjson.ReadGoTestJSON(strings.NewReader("not json\n")); err == nil {
+		t.Errorf("Expected an error for invalid input")
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	if _, ok := testyjson.ParseLine("    users_test.go:9: TestUsers: all tests passed"); ok {
+		t.Errorf("Found an event in an ordinary line")
+	}
+	if _, ok := testyjson.ParseLine("testy-event: {broken"); ok {
+		t.Errorf("Found an event in a broken line")
+	}
+	e, ok := testyjson.ParseLine(`testy-event: {"kind":"log","message":"hi","attrs":{"id":"7"}}`)
+	if !ok || e.Kind != "log" || e.Message != "hi" || e.Attrs["id"] != "7" {
+		t.Errorf("Event was wrong: %+v", e)
+	}
+}
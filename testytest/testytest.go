@@ -0,0 +1,189 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package testytest helps test custom helpers built on testy.
+//
+// ExpectFailure runs a function with a testy.T facade around a Recorder,
+// which stands in for a testing.T, and returns what happened: the
+// failures and their locations, and whether the function stopped with
+// FailNow or SkipNow.  Assertion methods on the Result check it:
+//
+// 	func TestCheckEven(t *testing.T) {
+// 		r := testytest.ExpectFailure(func(is *testy.T) {
+// 			checkEven(is, 3) // Line 12
+// 		})
+// 		r.AssertFailCount(t, 1)
+// 		r.AssertMessage(t, 0, `3 was not even`)
+// 		r.AssertLocation(t, 0, "even_test.go", 12)
+// 	}
+package testytest
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/xdg/testy"
+	"github.com/xdg/testy/internal/recording"
+)
+
+// Recorder is a testing.TB that records what a test does instead of
+// reporting it.  FailNow and SkipNow end the goroutine they are called in,
+// as they do for a testing.T.  Besides the testing.TB methods Name, Fail,
+// FailNow, Failed, SkipNow, Skipped, Log, Logf, Error, Errorf, Fatal,
+// Fatalf, Skip, Skipf, Helper and Cleanup, it has RunCleanups, which calls
+// the functions registered with Cleanup in last added, first called order,
+// and Logs, which returns a copy of the messages logged directly to the
+// recorder.  Messages logged through a testy.T facade are in its Events
+// instead.  Methods of testing.TB that Recorder doesn't implement, like
+// TempDir, panic.
+type Recorder struct {
+	*recording.TB
+}
+
+// NewRecorder returns a Recorder whose Name method returns 'name'.
+func NewRecorder(name string) *Recorder {
+	return &Recorder{recording.New(nil, name)}
+}
+
+// Result describes what happened when ExpectFailure ran a function.
+type Result struct {
+	FailCount  int           // Failures counted by the facade
+	Failed     bool          // Whether the recorder was marked as failed
+	FailedNow  bool          // Whether the function stopped with FailNow
+	Skipped    bool          // Whether the function stopped with SkipNow
+	Panicked   bool          // Whether the function panicked
+	PanicValue interface{}   // The value passed to panic, if any
+	Events     []testy.Event // Every event reported through the facade
+	Output     []string      // The facade's log messages, as from Output
+}
+
+// ExpectFailure calls f with a testy.T facade around a new Recorder and
+// returns what happened.  The call is made on a new goroutine, so f may
+// use FailNow, Fatal, Require helpers or SkipNow, which end only that
+// goroutine.  Panics are recovered and recorded in the Result.  The
+// facade's events are not sent to reporters registered with
+// testy.AddReporter, so expected failures don't show up in JUnit or JSON
+// reports.
+func ExpectFailure(f func(is *testy.T)) Result {
+	rec := &Recorder{recording.NewScratch(nil, "testytest")}
+	is := testy.NewCase(rec, "testytest").ManualDone()
+
+	var res Result
+	returned := false
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if !returned {
+				if v := recover(); v != nil {
+					res.Panicked = true
+					res.PanicValue = v
+				}
+			}
+			rec.RunCleanups()
+		}()
+		f(is)
+		returned = true
+	}()
+	<-done
+
+	res.FailCount = is.FailCount()
+	res.Skipped = rec.Skipped()
+	res.Failed = rec.Failed()
+	res.FailedNow = res.Failed && !returned && !res.Skipped && !res.Panicked
+	res.Events = is.Events()
+	res.Output = is.Output()
+	return res
+}
+
+// Failures returns the failure events of the result that have messages,
+// which are those from failing helpers, Error, Errorf, Fatal and Fatalf.
+func (r Result) Failures() []testy.Event {
+	var out []testy.Event
+	for _, e := range r.Events {
+		if e.Kind == testy.EventFailure && e.Message != "" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// AssertPassed checks that the function recorded no failures and wasn't
+// skipped; if not, it reports an error to t with the facade's output.
+func (r Result) AssertPassed(t testing.TB) {
+	t.Helper()
+	if r.Failed || r.FailCount > 0 || r.Skipped || r.Panicked {
+		t.Errorf("Expected no failures, but got %d: %q", r.FailCount, r.Output)
+	}
+}
+
+// AssertFailCount checks that the facade counted n failures; if not, it
+// reports an error to t with the facade's output.
+func (r Result) AssertFailCount(t testing.TB, n int) {
+	t.Helper()
+	if r.FailCount != n {
+		t.Errorf("Expected %d failures, but got %d: %q", n, r.FailCount, r.Output)
+	}
+}
+
+// AssertFailedNow checks that the function was stopped by FailNow; if not,
+// it reports an error to t.
+func (r Result) AssertFailedNow(t testing.TB) {
+	t.Helper()
+	if !r.FailedNow {
+		t.Errorf("Expected the function to stop with FailNow, but it didn't: %q", r.Output)
+	}
+}
+
+// AssertSkipped checks that the function was stopped by SkipNow; if not,
+// it reports an error to t.
+func (r Result) AssertSkipped(t testing.TB) {
+	t.Helper()
+	if !r.Skipped {
+		t.Errorf("Expected the function to be skipped, but it wasn't: %q", r.Output)
+	}
+}
+
+// AssertMessage checks that failure i, counting from zero, has a message
+// matching the regular expression 'pattern'; if not, it reports an error
+// to t.
+func (r Result) AssertMessage(t testing.TB, i int, pattern string) {
+	t.Helper()
+	e, ok := r.failure(t, i)
+	if !ok {
+		return
+	}
+	if ok, err := regexp.MatchString(pattern, e.Message); err != nil {
+		t.Errorf("Invalid pattern %q: %v", pattern, err)
+	} else if !ok {
+		t.Errorf("Failure %d message didn't match %q: %q", i, pattern, e.Message)
+	}
+}
+
+// AssertLocation checks that failure i, counting from zero, was reported
+// at the given line of a file with the given base name; if not, it
+// reports an error to t.
+func (r Result) AssertLocation(t testing.TB, i int, file string, line int) {
+	t.Helper()
+	e, ok := r.failure(t, i)
+	if !ok {
+		return
+	}
+	if got := filepath.Base(e.File); got != file || e.Line != line {
+		t.Errorf("Failure %d was reported at %s:%d, not %s:%d", i, got, e.Line, file, line)
+	}
+}
+
+func (r Result) failure(t testing.TB, i int) (testy.Event, bool) {
+	t.Helper()
+	failures := r.Failures()
+	if i < 0 || i >= len(failures) {
+		t.Errorf("Expected failure %d, but there were %d: %q", i, len(failures), r.Output)
+		return testy.Event{}, false
+	}
+	return failures[i], true
+}
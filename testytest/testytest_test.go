@@ -0,0 +1,117 @@
+// Copyright 2015 by David A. Golden. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package testytest_test
+
+import (
+	"testing"
+
+	"github.com/xdg/testy"
+	"github.com/xdg/testy/testytest"
+)
+
+func checkEven(is *testy.T, n int) {
+	is.Helper()
+	if n%2 != 0 {
+		is.Errorf("%d was not even", n)
+	}
+}
+
+func TestExpectFailure(t *testing.T) {
+	r := testytest.ExpectFailure(func(is *testy.T) {
+		checkEven(is, 2)
+		checkEven(is, 3) // Line 26
+		is.Label("Row", 1).Equal(1, 2)
+		is.Fail()
+	})
+	r.AssertFailCount(t, 3)
+	r.AssertMessage(t, 0, `^3 was not even$`)
+	r.AssertLocation(t, 0, "testytest_test.go", 26)
+	r.AssertMessage(t, 1, `^Values were not equal:`)
+	if !r.Failed || r.FailedNow || r.Skipped || r.Panicked {
+		t.Errorf("Result was wrong: %+v", r)
+	}
+	if n := len(r.Failures()); n != 2 {
+		t.Errorf("Expected 2 failures with messages, got %d", n)
+	}
+	if r.Events[2].Label != "Row 1" {
+		t.Errorf("Label of event was wrong: %q", r.Events[2].Label)
+	}
+}
+
+func TestExpectFailureStops(t *testing.T) {
+	reached := false
+	r := testytest.ExpectFailure(func(is *testy.T) {
+		is.Require().True(false)
+		reached = true
+	})
+	if reached {
+		t.Errorf("Function continued after FailNow")
+	}
+	r.AssertFailedNow(t)
+	r.AssertFailCount(t, 1)
+	r.AssertMessage(t, 0, `Expression was not true: false`)
+
+	r = testytest.ExpectFailure(func(is *testy.T) {
+		is.Skip("not today")
+	})
+	r.AssertSkipped(t)
+	if r.FailedNow || r.FailCount != 0 {
+		t.Errorf("Result was wrong: %+v", r)
+	}
+
+	r = testytest.ExpectFailure(func(is *testy.T) {
+		panic("boom")
+	})
+	if !r.Panicked || r.PanicValue != "boom" {
+		t.Errorf("Panic wasn't recorded: %+v", r)
+	}
+
+	testytest.ExpectFailure(func(is *testy.T) {
+		checkEven(is, 4)
+	}).AssertPassed(t)
+}
+
+func TestResultAssertions(t *testing.T) {
+	r := testytest.ExpectFailure(func(is *testy.T) {
+		is.Error("oops") // Line 81
+	})
+
+	// Run the assertions against a recorder to check that they fail.
+	rec := testytest.NewRecorder("assertions")
+	r.AssertPassed(rec)
+	r.AssertFailCount(rec, 2)
+	r.AssertFailedNow(rec)
+	r.AssertSkipped(rec)
+	r.AssertMessage(rec, 0, `^nope$`)
+	r.AssertMessage(rec, 1, `oops`)
+	r.AssertLocation(rec, 0, "testytest_test.go", 80)
+	r.AssertLocation(rec, 0, "testytest_test.go", 81)
+	r.AssertMessage(rec, 0, `^oops$`)
+
+	if logs := rec.Logs(); len(logs) != 7 {
+		t.Errorf("Expected 7 assertion failures, got %d: %q", len(logs), logs)
+	}
+}
+
+func TestExpectFailureIsNotReported(t *testing.T) {
+	var events []testy.Event
+	remove := testy.AddReporter(testy.ReporterFunc(func(e testy.Event) {
+		events = append(events, e)
+	}))
+	defer remove()
+
+	r := testytest.ExpectFailure(func(is *testy.T) {
+		checkEven(is, 3)
+		is.Run("sub", func(is *testy.T) {
+			is.Error("expected")
+		})
+	})
+	r.AssertFailCount(t, 2)
+	if len(events) != 0 {
+		t.Errorf("Registered reporter got %d events: %+v", len(events), events)
+	}
+}